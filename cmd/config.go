@@ -0,0 +1,202 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// A config file key used for providing inline instance metadata, rather than a flag
+const metadataKey = "metadata"
+
+// Flags that cannot be set from within a config file
+var excludedConfigFlags = map[string]struct{}{
+	"config": {},
+	"help":   {},
+}
+
+// Options loaded from a config file that do not map onto a flag
+type config struct {
+	metadata []byte
+}
+
+// Loads a YAML or JSON config file, setting any flag that has not been explicitly
+// provided on the command line. Each key within the config file mirrors the name
+// of a flag
+func loadConfig(path string, cmd *cobra.Command) (config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg, err := applyConfig(data, cmd.Flags())
+	if err != nil {
+		return config{}, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	// Ensure options set within the config file do not conflict
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return config{}, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+func applyConfig(data []byte, flags *pflag.FlagSet) (config, error) {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return config{}, err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var cfg config
+	for _, key := range keys {
+		if key == metadataKey {
+			if _, found := values["metadata-file"]; found {
+				return config{}, errors.New("metadata and metadata-file cannot both be set")
+			}
+
+			var err error
+			if cfg.metadata, err = json.Marshal(values[key]); err != nil {
+				return config{}, fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+			continue
+		}
+
+		flag := flags.Lookup(key)
+		if _, excluded := excludedConfigFlags[key]; excluded || flag == nil {
+			return config{}, fmt.Errorf("unknown key %q", key)
+		}
+
+		// Flags provided on the command line take precedence
+		if flag.Changed {
+			continue
+		}
+
+		value, err := flagValue(values[key])
+		if err != nil {
+			return config{}, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+
+		if err := flags.Set(key, value); err != nil {
+			return config{}, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Converts a config value into its equivalent flag representation. A map is
+// converted into a comma separated list of key value pairs
+func flagValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(v))
+		for key, val := range v {
+			if _, nested := val.(map[string]interface{}); nested {
+				return "", fmt.Errorf("%s must not contain a nested object", key)
+			}
+			pairs = append(pairs, fmt.Sprintf("%s=%v", key, val))
+		}
+		sort.Strings(pairs)
+
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write(pairs) // nolint: errcheck
+		w.Flush()
+
+		return strings.TrimSuffix(buf.String(), "\n"), w.Error()
+	case []interface{}:
+		return "", errors.New("a list is not supported")
+	}
+
+	return fmt.Sprint(value), nil
+}
+
+func newConfigCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage config files used to configure the imds-mock",
+	}
+
+	validate := &cobra.Command{
+		Use:   "validate <file>",
+		Short: "validate a YAML or JSON config file without starting the imds-mock",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validateConfig(out, args[0])
+		},
+	}
+
+	cmd.AddCommand(validate)
+	return cmd
+}
+
+func validateConfig(out io.Writer, path string) error {
+	// Build the options exactly as the root command would, initialising the mock
+	// without serving any requests or writing anything on close
+	root := newRootCmd(io.Discard, func(opts imds.Options) error {
+		opts.AutoStart = false
+		opts.LogLevel = "off"
+		opts.SpotTermination = imds.NoTermination
+		opts.HARFile = ""
+
+		m, err := imds.New(opts)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		m.Close()
+
+		return nil
+	})
+	root.SetArgs([]string{"--config", path})
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+
+	if err := root.Execute(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s is valid\n", path)
+	return nil
+}
@@ -0,0 +1,255 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	return file
+}
+
+func execRoot(t *testing.T, args ...string) (imds.Options, error) {
+	t.Helper()
+
+	var opts imds.Options
+	cmd := newRootCmd(io.Discard, func(o imds.Options) error {
+		opts = o
+		return nil
+	})
+	cmd.SetArgs(args)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	return opts, err
+}
+
+func TestConfigYAML(t *testing.T) {
+	file := writeConfig(t, "imds-mock.yaml", `port: 1400
+imdsv2: true
+instance-tags:
+  Name: ci-runner
+  Environment: ci,testing
+spot: true
+spot-action: stop=10s
+spot-notice-lead: 30s
+metadata:
+  instance-type: t3.micro
+`)
+
+	opts, err := execRoot(t, "--config", file)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1400, opts.Port)
+	assert.True(t, opts.IMDSv2)
+	assert.Equal(t, map[string]string{"Name": "ci-runner", "Environment": "ci,testing"}, opts.InstanceTags)
+	assert.True(t, opts.Spot)
+	assert.Equal(t, imds.SpotActionEvent{Action: patch.StopSpotInstanceAction, Duration: 10 * time.Second}, opts.SpotAction)
+	assert.Equal(t, 30*time.Second, opts.SpotNoticeLead)
+	assert.JSONEq(t, `{"instance-type":"t3.micro"}`, string(opts.Metadata))
+}
+
+func TestConfigJSON(t *testing.T) {
+	file := writeConfig(t, "imds-mock.json", `{"pretty": true, "iam-role": "ci-access"}`)
+
+	opts, err := execRoot(t, "--config", file)
+	require.NoError(t, err)
+
+	assert.True(t, opts.Pretty)
+	assert.Equal(t, "ci-access", opts.IAMRole)
+	assert.Equal(t, imds.DefaultOptions.Port, opts.Port)
+}
+
+func TestConfigFlagsTakePrecedence(t *testing.T) {
+	file := writeConfig(t, "imds-mock.yaml", `port: 1400
+iam-role: ci-access
+`)
+
+	opts, err := execRoot(t, "--config", file, "--port", "1500")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1500, opts.Port)
+	assert.Equal(t, "ci-access", opts.IAMRole)
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		errMsg string
+	}{
+		{
+			name:   "UnknownKey",
+			config: "imdsv3: true",
+			errMsg: `unknown key "imdsv3"`,
+		},
+		{
+			name:   "ExcludedKey",
+			config: "config: other.yaml",
+			errMsg: `unknown key "config"`,
+		},
+		{
+			name:   "InvalidValue",
+			config: "port: abc",
+			errMsg: "invalid value for port",
+		},
+		{
+			name:   "UnsupportedList",
+			config: "instance-tags: [Name]",
+			errMsg: "invalid value for instance-tags: a list is not supported",
+		},
+		{
+			name: "MetadataAndMetadataFile",
+			config: `metadata-file: metadata.json
+metadata: {}`,
+			errMsg: "metadata and metadata-file cannot both be set",
+		},
+		{
+			name: "MutuallyExclusiveKeys",
+			config: `user-data: inline
+user-data-file: user-data.sh`,
+			errMsg: "if any flags in the group [user-data user-data-base64 user-data-file] are set none of the others can be",
+		},
+		{
+			name:   "MalformedYAML",
+			config: "port: [",
+			errMsg: "invalid config file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeConfig(t, "imds-mock.yaml", tt.config)
+
+			_, err := execRoot(t, "--config", file)
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	file := writeConfig(t, "imds-mock.yaml", `spot: true
+spot-termination: exit
+`)
+
+	var buf bytes.Buffer
+	cmd := newConfigCmd(&buf)
+	cmd.SetArgs([]string{"validate", file})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, file+" is valid\n", buf.String())
+}
+
+func TestConfigValidateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		errMsg string
+	}{
+		{
+			name:   "UnknownKey",
+			config: "imdsv3: true",
+			errMsg: `unknown key "imdsv3"`,
+		},
+		{
+			name:   "UnsupportedTerminationMode",
+			config: "spot-termination: explode",
+			errMsg: "explode is not a supported termination mode",
+		},
+		{
+			name:   "MetadataNotAnObject",
+			config: "metadata: [1, 2]",
+			errMsg: "metadata must be a JSON object",
+		},
+		{
+			name:   "UnsupportedLogFormat",
+			config: "log-format: xml",
+			errMsg: "xml is not a supported log format",
+		},
+		{
+			name:   "UnsupportedLogLevel",
+			config: "log-level: verbose",
+			errMsg: "verbose is not a supported log level",
+		},
+		{
+			name: "ReservedInstanceTag",
+			config: `instance-tags:
+  aws:foo: bar`,
+			errMsg: `invalid instance tag key "aws:foo": the aws: prefix is reserved for use by AWS`,
+		},
+		{
+			name:   "NegativeCredentialsTTL",
+			config: "iam-credentials-ttl: -1s",
+			errMsg: "iam credentials ttl must be greater than zero",
+		},
+		{
+			name: "NegativeSpotNoticeLead",
+			config: `spot: true
+spot-notice-lead: -5s`,
+			errMsg: "spot notice lead cannot be negative",
+		},
+		{
+			name: "MissingIdentityKeyFile",
+			config: `identity-key-file: does-not-exist.pem
+identity-cert-file: does-not-exist.crt`,
+			errMsg: "failed to read identity key file",
+		},
+		{
+			name:   "MissingUserDataFile",
+			config: "user-data-file: does-not-exist.sh",
+			errMsg: "failed to read user data file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeConfig(t, "imds-mock.yaml", tt.config)
+
+			var buf bytes.Buffer
+			cmd := newConfigCmd(&buf)
+			cmd.SetArgs([]string{"validate", file})
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			err := cmd.Execute()
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "IMDS_MOCK_SPOT_ACTION", envName("spot-action"))
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("IMDS_MOCK_SPOT", "true")
+	t.Setenv("IMDS_MOCK_SPOT_ACTION", "stop=30s")
+	t.Setenv("IMDS_MOCK_INSTANCE_TAGS", "Name=x,Env=dev")
+	t.Setenv("IMDS_MOCK_IAM_ROLE", "")
+
+	opts, err := execRoot(t)
+	require.NoError(t, err)
+
+	assert.True(t, opts.Spot)
+	assert.Equal(t, imds.SpotActionEvent{Action: patch.StopSpotInstanceAction, Duration: 30 * time.Second}, opts.SpotAction)
+	assert.Equal(t, map[string]string{"Name": "x", "Env": "dev"}, opts.InstanceTags)
+	assert.Empty(t, opts.IAMRole)
+	assert.True(t, opts.ExcludeIAM)
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	file := writeConfig(t, "imds-mock.yaml", `port: 1400
+pretty: true
+iam-role: config-access
+`)
+	t.Setenv("IMDS_MOCK_CONFIG", file)
+	t.Setenv("IMDS_MOCK_PORT", "1500")
+	t.Setenv("IMDS_MOCK_IAM_ROLE", "env-access")
+
+	opts, err := execRoot(t, "--iam-role", "flag-access")
+	require.NoError(t, err)
+
+	assert.Equal(t, "flag-access", opts.IAMRole)
+	assert.Equal(t, 1500, opts.Port)
+	assert.True(t, opts.Pretty)
+}
+
+func TestEnvErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		errMsg string
+	}{
+		{
+			name:   "InvalidValue",
+			env:    map[string]string{"IMDS_MOCK_SPOT_ACTION": "reboot=1s"},
+			errMsg: "invalid value for environment variable IMDS_MOCK_SPOT_ACTION",
+		},
+		{
+			name: "MutuallyExclusive",
+			env: map[string]string{
+				"IMDS_MOCK_USER_DATA":      "inline",
+				"IMDS_MOCK_USER_DATA_FILE": "user-data.sh",
+			},
+			errMsg: "if any flags in the group [user-data user-data-base64 user-data-file] are set none of the others can be",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := execRoot(t)
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
+	"github.com/spf13/cobra"
+)
+
+type reportOptions struct {
+	adminURL string
+	json     bool
+}
+
+func newReportCmd(out io.Writer) *cobra.Command {
+	opts := reportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report the metadata categories accessed through a running imds-mock",
+		Long: `Report every metadata category accessed through the admin API of a running imds-mock, along with
+how often it was accessed using IMDSv1 and IMDSv2. Ideal for identifying the categories an application
+actually depends on`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(out)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.adminURL, "admin-url", defaultAdminURL, "the URL of the admin API exposed by the imds-mock")
+	flags.BoolVar(&opts.json, "json", false, "print the report as JSON")
+
+	return cmd
+}
+
+func (o reportOptions) run(out io.Writer) error {
+	report, err := o.fetch()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve category usage report: %w", err)
+	}
+
+	if o.json {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tCOUNT\tV1\tV2")
+	for _, usage := range report.Categories {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", usage.Category, usage.Count, usage.V1, usage.V2)
+	}
+
+	return w.Flush()
+}
+
+func (o reportOptions) fetch() (audit.UsageReport, error) {
+	var report audit.UsageReport
+
+	resp, err := http.Get(strings.TrimSuffix(o.adminURL, "/") + "/usage")
+	if err != nil {
+		return report, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return report, adminError(resp)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&report)
+	return report, err
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestReport(t *testing.T) {
+	m, url := adminServer(t)
+
+	for _, path := range []string{"/latest/meta-data/instance-id", "/latest/meta-data/instance-id", "/latest/meta-data/iam/security-credentials/ssm-access"} {
+		req, _ := http.NewRequest(http.MethodGet, path, http.NoBody)
+		m.Router().ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var buf bytes.Buffer
+	cmd := newReportCmd(&buf)
+	cmd.SetArgs([]string{"--admin-url", url})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, `CATEGORY                                    COUNT   V1   V2
+meta-data/instance-id                       2       2    0
+meta-data/iam/security-credentials/{role}   1       1    0
+`, buf.String())
+}
+
+func TestReportJSON(t *testing.T) {
+	m, url := adminServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	m.Router().ServeHTTP(httptest.NewRecorder(), req)
+
+	var buf bytes.Buffer
+	cmd := newReportCmd(&buf)
+	cmd.SetArgs([]string{"--admin-url", url, "--json"})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, "meta-data/instance-id", gjson.Get(buf.String(), "categories.0.category").String())
+	assert.Equal(t, int64(1), gjson.Get(buf.String(), "categories.0.v1").Int())
+}
+
+func TestReport_Unavailable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	cmd := newReportCmd(&buf)
+	cmd.SetArgs([]string{"--admin-url", srv.URL})
+	cmd.SilenceUsage = true
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "failed to retrieve category usage report: unexpected response from admin API: 404 Not Found")
+}
@@ -24,14 +24,22 @@ package cmd
 
 import (
 	ctx "context"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/identity"
+	"github.com/purpleclay/imds-mock/pkg/imds/logging"
 	"github.com/purpleclay/imds-mock/pkg/imds/patch"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Custom flag for parsing a spot action
@@ -49,17 +57,9 @@ func (e *spotActionFlag) Set(value string) error {
 		return fmt.Errorf("%s must be formatted as key=value e.g. terminate=2s", value)
 	}
 
-	spotAction := patch.SpotInstanceAction(action)
-	switch spotAction {
-	case patch.TerminateSpotInstanceAction:
-		fallthrough
-	case patch.HibernateSpotInstanceAction:
-		fallthrough
-	case patch.StopSpotInstanceAction:
-		break
-	default:
-		return fmt.Errorf("%s is not a supported spot action expecting (%s, %s or %s)",
-			action, patch.TerminateSpotInstanceAction, patch.StopSpotInstanceAction, patch.HibernateSpotInstanceAction)
+	spotAction, err := patch.ParseSpotInstanceAction(action)
+	if err != nil {
+		return err
 	}
 
 	eventTime, err := time.ParseDuration(duration)
@@ -79,17 +79,180 @@ func (e *spotActionFlag) Type() string {
 	return "stringToString"
 }
 
+// Options for sourcing user data, only one of these can be set
+type userDataOptions struct {
+	inline string
+	file   string
+	base64 string
+}
+
+func (o userDataOptions) read() ([]byte, error) {
+	switch {
+	case o.file != "":
+		data, err := os.ReadFile(o.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read user data file: %w", err)
+		}
+		return data, nil
+	case o.base64 != "":
+		data, err := base64.StdEncoding.DecodeString(o.base64)
+		if err != nil {
+			return nil, fmt.Errorf("user data is not a valid base64 encoded string: %w", err)
+		}
+		return data, nil
+	case o.inline != "":
+		return []byte(o.inline), nil
+	}
+
+	return nil, nil
+}
+
+// Options for loading or exporting the key pair used to sign the instance identity document
+type identityOptions struct {
+	keyFile  string
+	certFile string
+	certOut  string
+}
+
+func (o identityOptions) resolve(opts *imds.Options) error {
+	if o.keyFile != "" {
+		var err error
+		if opts.IdentityKey, err = os.ReadFile(o.keyFile); err != nil {
+			return fmt.Errorf("failed to read identity key file: %w", err)
+		}
+
+		if opts.IdentityCertificate, err = os.ReadFile(o.certFile); err != nil {
+			return fmt.Errorf("failed to read identity certificate file: %w", err)
+		}
+	}
+
+	if o.certOut == "" {
+		return nil
+	}
+
+	// A key pair must exist before its certificate can be exported
+	if len(opts.IdentityKey) == 0 {
+		signer, err := identity.NewSigner()
+		if err != nil {
+			return err
+		}
+
+		opts.IdentityKey = signer.KeyPEM()
+		opts.IdentityCertificate = signer.CertificatePEM()
+	}
+
+	if err := os.WriteFile(o.certOut, opts.IdentityCertificate, 0o644); err != nil {
+		return fmt.Errorf("failed to export identity certificate: %w", err)
+	}
+
+	return nil
+}
+
+// How long to wait for active connections to drain during shutdown
+const shutdownTimeout = 10 * time.Second
+
 func Execute(out io.Writer) error {
+	// All logging is managed by the mock, prevent gin from writing its own debug output
+	gin.SetMode(gin.ReleaseMode)
+
+	return newRootCmd(out, serve).ExecuteContext(ctx.Background())
+}
+
+// Serves the IMDS mock until either an interrupt or termination signal is received
+func serve(opts imds.Options) error {
+	signalCtx, stop := signal.NotifyContext(ctx.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv, err := imds.NewServer(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := srv.Start(ctx.Background()); err != nil {
+		srv.Mock().Close()
+		return err
+	}
+
+	// Stop if either server fails, rather than waiting on a signal
+	select {
+	case <-signalCtx.Done():
+	case <-srv.Done():
+	}
+
+	shutdownCtx, cancel := ctx.WithTimeout(ctx.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	return srv.Err()
+}
+
+// Parses and validates any options that cannot be bound directly to a flag
+func parseOptions(flags *pflag.FlagSet, opts *imds.Options) error {
+	var err error
+	if opts.SpotTermination, err = imds.ParseTerminationMode(flags.Lookup("spot-termination").Value.String()); err != nil {
+		return err
+	}
+
+	if opts.LogFormat, err = logging.ParseFormat(flags.Lookup("log-format").Value.String()); err != nil {
+		return err
+	}
+
+	// An explicit empty role removes all IAM categories
+	opts.ExcludeIAM = opts.IAMRole == ""
+
+	// An explicit lead of zero requests an immediate interruption notice
+	opts.SpotNoticeImmediate = opts.SpotNoticeLead == 0
+
+	return logging.ValidateLevel(flags.Lookup("log-level").Value.String())
+}
+
+func newRootCmd(out io.Writer, serve func(imds.Options) error) *cobra.Command {
 	opts := imds.DefaultOptions
 
 	// flag to parse custom spot action
 	var spotAction spotActionFlag
 
+	// flags for sourcing user data
+	var userData userDataOptions
+
+	// flags for managing the instance identity key pair
+	var identityKeys identityOptions
+
+	// flag for loading a custom metadata document
+	var metadataFile string
+
+	// flag for configuring how a spot instance is terminated
+	var spotTermination string
+
+	// flag for configuring the format of written logs
+	var logFormat string
+
+	// flag for loading options from a config file
+	var configFile string
+
 	rootCmd := &cobra.Command{
 		Use:          "imds-mock",
 		Short:        "Easy mocking of the Amazon EC2 Instance Metadata Service (IMDS)",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadEnv(cmd); err != nil {
+				return err
+			}
+
+			if configFile != "" {
+				cfg, err := loadConfig(configFile, cmd)
+				if err != nil {
+					return err
+				}
+
+				if metadataFile == "" {
+					opts.Metadata = cfg.metadata
+				}
+			}
+
 			if spotAction.event != nil {
 				// Overwrite the default spot action, since a custom one has been provided
 				opts.SpotAction = imds.SpotActionEvent{
@@ -98,23 +261,73 @@ func Execute(out io.Writer) error {
 				}
 			}
 
-			_, err := imds.ServeWith(opts)
-			return err
+			if err := parseOptions(cmd.Flags(), &opts); err != nil {
+				return err
+			}
+
+			var err error
+			if opts.UserData, err = userData.read(); err != nil {
+				return err
+			}
+
+			if err = identityKeys.resolve(&opts); err != nil {
+				return err
+			}
+
+			if metadataFile != "" {
+				if opts.Metadata, err = os.ReadFile(metadataFile); err != nil {
+					return fmt.Errorf("failed to read metadata file: %w", err)
+				}
+			}
+
+			return serve(opts)
 		},
 	}
 
 	flags := rootCmd.Flags()
+	flags.IntVar(&opts.AdminPort, "admin-port", imds.DefaultOptions.AdminPort, "enable the admin API on this port for changing instance metadata at runtime")
+	flags.BoolVar(&opts.AuditV1, "audit-imdsv1", imds.DefaultOptions.AuditV1, "serve IMDSv1 requests as normal, but log and count them separately")
+	flags.StringVar(&configFile, "config", "", "path to a YAML or JSON config file of options, any flag takes precedence")
 	flags.BoolVar(&opts.ExcludeInstanceTags, "exclude-instance-tags", imds.DefaultOptions.ExcludeInstanceTags, "exclude access to instance tags associated with the instance")
+	flags.StringVar(&opts.HARFile, "har-file", imds.DefaultOptions.HARFile, "on shutdown, write all request and response pairs to this path as an HTTP Archive (HAR)")
+	flags.IntVar(&opts.HARSize, "har-size", imds.DefaultOptions.HARSize, "the maximum number of the most recent request and response pairs written to the HAR file")
+	flags.StringVar(&opts.IAMRole, "iam-role", imds.DefaultOptions.IAMRole, "the name of the IAM role attached to the instance, an empty name removes all IAM categories")
+	flags.DurationVar(&opts.CredentialsTTL, "iam-credentials-ttl", imds.DefaultOptions.CredentialsTTL, "the lifetime of any temporary security credentials before they are rotated")
+	flags.StringVar(&opts.InstanceProfileArn, "instance-profile-arn", imds.DefaultOptions.InstanceProfileArn, "the ARN of the instance profile, derived from the IAM role by default")
 	flags.BoolVar(&opts.IMDSv2, "imdsv2", imds.DefaultOptions.IMDSv2, "enforce IMDSv2 requiring all requests to contain a valid metadata token")
 	flags.StringToStringVar(&opts.InstanceTags, "instance-tags", imds.DefaultOptions.InstanceTags, "a list of instance tags (key pairs) to expose as metadata")
+	flags.IntVar(&opts.JournalSize, "journal-size", imds.DefaultOptions.JournalSize, "the maximum number of requests recorded within the journal exposed by the admin API")
+	flags.StringVar(&opts.LogFile, "log-file", imds.DefaultOptions.LogFile, "path to a file that logs are appended to instead of stderr")
+	flags.StringVar(&logFormat, "log-format", string(imds.DefaultOptions.LogFormat), "the format of written logs, either json or console")
+	flags.StringVar(&opts.LogLevel, "log-level", imds.DefaultOptions.LogLevel, "the minimum level of written logs, either debug, info, warn, error or off")
+	flags.StringVar(&metadataFile, "metadata-file", "", "path to a JSON document that replaces the default instance metadata")
+	flags.BoolVar(&opts.MergeMetadata, "metadata-merge", imds.DefaultOptions.MergeMetadata, "deep merge the metadata file onto the default instance metadata rather than replacing it")
 	flags.IntVar(&opts.Port, "port", imds.DefaultOptions.Port, "the port to be used at startup")
 	flags.BoolVar(&opts.Pretty, "pretty", imds.DefaultOptions.Pretty, "if instance categories should return pretty printed JSON")
 	flags.BoolVar(&opts.Spot, "spot", imds.DefaultOptions.Spot, "enable simulation of a spot instance and interruption notice")
 	flags.Var(&spotAction, "spot-action", "configure the type and delay of the spot interruption notice")
+	flags.DurationVar(&opts.SpotNoticeLead, "spot-notice-lead", imds.DefaultOptions.SpotNoticeLead, "how far in advance of the spot instance being interrupted the interruption notice is raised, 0s raises it immediately")
+	flags.StringVar(&spotTermination, "spot-termination", string(imds.DefaultOptions.SpotTermination), "once a terminate or stop notice has passed, either refuse connections, hang, or exit with code 143")
+	flags.DurationVar(&opts.SpotRebalanceLead, "spot-rebalance-lead", imds.DefaultOptions.SpotRebalanceLead, "how far in advance of the interruption notice a rebalance recommendation is raised")
+	flags.BoolVar(&opts.SpotNoNotice, "spot-no-notice", imds.DefaultOptions.SpotNoNotice, "start the spot instance without an interruption notice, raising one through the admin API")
+	rootCmd.MarkFlagsMutuallyExclusive("spot-action", "spot-no-notice")
+	flags.StringVar(&opts.TokenSecret, "token-secret", imds.DefaultOptions.TokenSecret, "pin the secret used to sign IMDSv2 session tokens, a random secret is generated by default")
+	flags.BoolVar(&opts.UsageReport, "usage-report", imds.DefaultOptions.UsageReport, "log a report of every metadata category accessed on shutdown")
+	flags.StringVar(&userData.inline, "user-data", "", "a string to expose as user data")
+	flags.StringVar(&userData.base64, "user-data-base64", "", "a base64 encoded blob to decode and expose as user data")
+	flags.StringVar(&userData.file, "user-data-file", "", "path to a file to expose as user data, contents are served unchanged")
+	rootCmd.MarkFlagsMutuallyExclusive("user-data", "user-data-base64", "user-data-file")
+	flags.StringVar(&identityKeys.keyFile, "identity-key-file", "", "path to a PEM encoded RSA private key for signing the instance identity document")
+	flags.StringVar(&identityKeys.certFile, "identity-cert-file", "", "path to the PEM encoded certificate associated with the identity key")
+	flags.StringVar(&identityKeys.certOut, "identity-cert-out", "", "export the PEM encoded certificate for verifying instance identity signatures to this path")
+	rootCmd.MarkFlagsRequiredTogether("identity-key-file", "identity-cert-file")
 
 	rootCmd.AddCommand(newVersionCmd(out))
 	rootCmd.AddCommand(newManPagesCmd(out))
 	rootCmd.AddCommand(newCompletionCmd(out))
+	rootCmd.AddCommand(newSpotCmd(out))
+	rootCmd.AddCommand(newConfigCmd(out))
+	rootCmd.AddCommand(newReportCmd(out))
 
-	return rootCmd.ExecuteContext(ctx.Background())
+	return rootCmd
 }
@@ -23,8 +23,11 @@ SOFTWARE.
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/purpleclay/imds-mock/pkg/imds/logging"
 	"github.com/purpleclay/imds-mock/pkg/imds/patch"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -83,3 +86,104 @@ func TestSpotActionFlagType(t *testing.T) {
 
 	assert.Equal(t, "stringToString", flag.Type())
 }
+
+func TestUserDataRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "user-data")
+	require.NoError(t, os.WriteFile(file, []byte{0x1f, 0x8b, 0x08}, 0o600))
+
+	tests := []struct {
+		name     string
+		opts     userDataOptions
+		expected []byte
+	}{
+		{
+			name:     "Inline",
+			opts:     userDataOptions{inline: "#!/bin/bash"},
+			expected: []byte("#!/bin/bash"),
+		},
+		{
+			name:     "Base64",
+			opts:     userDataOptions{base64: "IyEvYmluL2Jhc2g="},
+			expected: []byte("#!/bin/bash"),
+		},
+		{
+			name:     "File",
+			opts:     userDataOptions{file: file},
+			expected: []byte{0x1f, 0x8b, 0x08},
+		},
+		{
+			name:     "None",
+			opts:     userDataOptions{},
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.opts.read()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
+func TestUserDataReadError(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   userDataOptions
+		errMsg string
+	}{
+		{
+			name:   "InvalidBase64",
+			opts:   userDataOptions{base64: "not base64"},
+			errMsg: "user data is not a valid base64 encoded string",
+		},
+		{
+			name:   "MissingFile",
+			opts:   userDataOptions{file: "does-not-exist"},
+			errMsg: "failed to read user data file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.opts.read()
+
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestLogFlags(t *testing.T) {
+	opts, err := execRoot(t, "--log-level", "debug", "--log-format", "console", "--log-file", "imds-mock.log")
+	require.NoError(t, err)
+
+	assert.Equal(t, "debug", opts.LogLevel)
+	assert.Equal(t, logging.ConsoleFormat, opts.LogFormat)
+	assert.Equal(t, "imds-mock.log", opts.LogFile)
+}
+
+func TestLogLevelUnsupported(t *testing.T) {
+	_, err := execRoot(t, "--log-level", "verbose")
+
+	require.EqualError(t, err, "verbose is not a supported log level expecting (debug, info, warn, error or off)")
+}
+
+func TestSpotNoticeLeadZeroIsImmediate(t *testing.T) {
+	opts, err := execRoot(t, "--spot", "--spot-notice-lead", "0s")
+	require.NoError(t, err)
+
+	assert.True(t, opts.SpotNoticeImmediate)
+}
+
+func TestTokenSecretFlag(t *testing.T) {
+	opts, err := execRoot(t, "--token-secret", "pinned")
+	require.NoError(t, err)
+
+	assert.Equal(t, "pinned", opts.TokenSecret)
+}
+
+func TestLogFormatUnsupported(t *testing.T) {
+	_, err := execRoot(t, "--log-format", "xml")
+
+	require.EqualError(t, err, "xml is not a supported log format expecting (json or console)")
+}
@@ -0,0 +1,154 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"github.com/spf13/cobra"
+)
+
+const defaultAdminURL = "http://localhost:1339"
+
+type spotOptions struct {
+	adminURL string
+	action   string
+}
+
+func newSpotCmd(out io.Writer) *cobra.Command {
+	opts := spotOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "spot",
+		Short: "Manage the interruption of a running spot instance",
+		Long:  "Raise or withdraw a spot interruption notice or rebalance recommendation through the admin API of a running imds-mock",
+	}
+	cmd.PersistentFlags().StringVar(&opts.adminURL, "admin-url", defaultAdminURL, "the URL of the admin API exposed by the imds-mock")
+
+	interrupt := &cobra.Command{
+		Use:       "interrupt [terminate|stop|hibernate]",
+		Short:     "raise a spot interruption notice, defaults to terminate",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"terminate", "stop", "hibernate"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.action = string(patch.TerminateSpotInstanceAction)
+			if len(args) > 0 {
+				opts.action = args[0]
+			}
+			return opts.interrupt(out)
+		},
+	}
+
+	rebalance := &cobra.Command{
+		Use:   "rebalance",
+		Short: "raise a rebalance recommendation ahead of any interruption notice",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.rebalance(out)
+		},
+	}
+
+	withdraw := &cobra.Command{
+		Use:   "withdraw",
+		Short: "withdraw a raised spot interruption notice",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.withdraw(out)
+		},
+	}
+
+	cmd.AddCommand(interrupt, rebalance, withdraw)
+	return cmd
+}
+
+func (o spotOptions) interrupt(out io.Writer) error {
+	action, err := patch.ParseSpotInstanceAction(o.action)
+	if err != nil {
+		return err
+	}
+
+	body, _ := json.Marshal(imds.SpotInterruption{Action: action})
+	if err := o.send(http.MethodPost, "/spot/interruption", body); err != nil {
+		return fmt.Errorf("failed to raise spot interruption notice: %w", err)
+	}
+
+	fmt.Fprintf(out, "raised spot interruption notice to %s instance\n", action)
+	return nil
+}
+
+func (o spotOptions) rebalance(out io.Writer) error {
+	if err := o.send(http.MethodPost, "/spot/rebalance", nil); err != nil {
+		return fmt.Errorf("failed to raise rebalance recommendation: %w", err)
+	}
+
+	fmt.Fprintln(out, "raised rebalance recommendation")
+	return nil
+}
+
+func (o spotOptions) withdraw(out io.Writer) error {
+	if err := o.send(http.MethodDelete, "/spot/interruption", nil); err != nil {
+		return fmt.Errorf("failed to withdraw spot interruption notice: %w", err)
+	}
+
+	fmt.Fprintln(out, "withdrawn spot interruption notice")
+	return nil
+}
+
+func (o spotOptions) send(method, path string, body []byte) error {
+	req, err := http.NewRequest(method, strings.TrimSuffix(o.adminURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return adminError(resp)
+}
+
+// Extracts the error returned by the admin API
+func adminError(resp *http.Response) error {
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Error == "" {
+		return fmt.Errorf("unexpected response from admin API: %s", resp.Status)
+	}
+
+	return fmt.Errorf("%s", apiErr.Error)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func adminServer(t *testing.T) (*imds.Mock, string) {
+	t.Helper()
+
+	opts := imds.DefaultOptions
+	opts.AutoStart = false
+	opts.Spot = true
+	opts.SpotNoNotice = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(m.AdminRouter())
+	t.Cleanup(srv.Close)
+
+	return m, srv.URL
+}
+
+func instanceAction(t *testing.T, m *imds.Mock) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/spot/instance-action", http.NoBody)
+	m.Router().ServeHTTP(w, req)
+
+	return w
+}
+
+func TestSpotInterrupt(t *testing.T) {
+	m, url := adminServer(t)
+
+	var buf bytes.Buffer
+	cmd := newSpotCmd(&buf)
+	cmd.SetArgs([]string{"interrupt", "hibernate", "--admin-url", url})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, "raised spot interruption notice to hibernate instance\n", buf.String())
+	assert.Equal(t, "hibernate", gjson.Get(instanceAction(t, m).Body.String(), "action").String())
+}
+
+func TestSpotInterrupt_UnsupportedAction(t *testing.T) {
+	_, url := adminServer(t)
+
+	var buf bytes.Buffer
+	cmd := newSpotCmd(&buf)
+	cmd.SetArgs([]string{"interrupt", "reboot", "--admin-url", url})
+	cmd.SilenceUsage = true
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "reboot is not a supported spot action expecting (terminate, stop or hibernate)")
+}
+
+func TestSpotRebalance(t *testing.T) {
+	m, url := adminServer(t)
+
+	var buf bytes.Buffer
+	cmd := newSpotCmd(&buf)
+	cmd.SetArgs([]string{"rebalance", "--admin-url", url})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, "raised rebalance recommendation\n", buf.String())
+	assert.Equal(t, http.StatusNotFound, instanceAction(t, m).Code)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/events/recommendations/rebalance", http.NoBody)
+	m.Router().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSpotWithdraw(t *testing.T) {
+	m, url := adminServer(t)
+	require.NoError(t, m.InterruptSpot("stop"))
+
+	var buf bytes.Buffer
+	cmd := newSpotCmd(&buf)
+	cmd.SetArgs([]string{"withdraw", "--admin-url", url})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, "withdrawn spot interruption notice\n", buf.String())
+	assert.Equal(t, http.StatusNotFound, instanceAction(t, m).Code)
+}
@@ -0,0 +1,228 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"go.uber.org/zap"
+)
+
+const (
+	// JSONPatchContentType defines the content type of a JSON Patch document, as
+	// accepted by the admin API, see: https://www.rfc-editor.org/rfc/rfc6902
+	JSONPatchContentType = "application/json-patch+json"
+
+	// MergePatchContentType defines the content type of a JSON Merge Patch document,
+	// as accepted by the admin API, see: https://www.rfc-editor.org/rfc/rfc7396
+	MergePatchContentType = "application/merge-patch+json"
+)
+
+// SpotInterruption defines the body of a request to the admin API for raising
+// a spot interruption notice
+type SpotInterruption struct {
+	Action patch.SpotInstanceAction `json:"action"`
+}
+
+func (m *Mock) adminRouter(logger *zap.Logger) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.ZapLogger(logger), middleware.ZapRecovery(logger))
+
+	// see: https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies
+	r.SetTrustedProxies(nil)
+
+	r.GET("/metadata", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", m.metadata.Bytes())
+	})
+
+	r.PATCH("/metadata", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortAdmin(c, http.StatusBadRequest, err)
+			return
+		}
+
+		var patcher patch.JSONPatcher
+
+		switch c.ContentType() {
+		case JSONPatchContentType:
+			patcher = patch.JSON{Document: body}
+			err = validateJSONPatch(body)
+		case MergePatchContentType:
+			patcher = patch.Merge{Document: body}
+			err = validateMergePatch(body)
+		default:
+			abortAdmin(c, http.StatusUnsupportedMediaType,
+				fmt.Errorf("unsupported content type, expecting either %s or %s", JSONPatchContentType, MergePatchContentType))
+			return
+		}
+
+		if err != nil {
+			abortAdmin(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := m.metadata.Patch(patcher); err != nil {
+			abortAdmin(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	r.GET("/journal", func(c *gin.Context) {
+		c.JSON(http.StatusOK, m.journal.Entries())
+	})
+
+	r.DELETE("/journal", func(c *gin.Context) {
+		m.journal.Clear()
+		c.Status(http.StatusNoContent)
+	})
+
+	r.GET("/audit/v1", func(c *gin.Context) {
+		if m.audit == nil {
+			abortAdmin(c, http.StatusNotFound, errors.New("auditing of IMDSv1 requests is not enabled"))
+			return
+		}
+
+		c.JSON(http.StatusOK, m.audit.Report())
+	})
+
+	r.GET("/usage", func(c *gin.Context) {
+		c.JSON(http.StatusOK, m.usage.Report())
+	})
+
+	r.GET("/metrics", gin.WrapH(m.metrics.Handler()))
+
+	r.GET("/tokens", func(c *gin.Context) {
+		c.JSON(http.StatusOK, m.tokens.Active())
+	})
+
+	r.DELETE("/tokens", func(c *gin.Context) {
+		m.tokens.RevokeAll()
+		c.Status(http.StatusNoContent)
+	})
+
+	r.DELETE("/tokens/:id", func(c *gin.Context) {
+		if !m.tokens.Revoke(c.Param("id")) {
+			abortAdmin(c, http.StatusNotFound, fmt.Errorf("no active session token with id %s", c.Param("id")))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	r.POST("/reboot", func(c *gin.Context) {
+		m.Reboot()
+		c.Status(http.StatusNoContent)
+	})
+
+	r.POST("/spot/interruption", func(c *gin.Context) {
+		interruption := SpotInterruption{Action: patch.TerminateSpotInstanceAction}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&interruption); err != nil {
+				abortAdmin(c, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		action, err := patch.ParseSpotInstanceAction(string(interruption.Action))
+		if err != nil {
+			abortAdmin(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := m.InterruptSpot(action); err != nil {
+			abortAdmin(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	r.POST("/spot/rebalance", func(c *gin.Context) {
+		if err := m.RecommendSpotRebalance(); err != nil {
+			abortAdmin(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	r.DELETE("/spot/interruption", func(c *gin.Context) {
+		if err := m.WithdrawSpotInterruption(); err != nil {
+			abortAdmin(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	return r
+}
+
+func abortAdmin(c *gin.Context, code int, err error) {
+	c.Error(err) // nolint: errcheck
+	c.AbortWithStatusJSON(code, gin.H{"error": err.Error()})
+}
+
+// Ensures each operation within a JSON Patch document contains a path
+func validateJSONPatch(doc []byte) error {
+	var ops []struct {
+		Path *string `json:"path"`
+	}
+
+	if err := json.Unmarshal(doc, &ops); err != nil {
+		return fmt.Errorf("invalid JSON patch document: %w", err)
+	}
+
+	for _, op := range ops {
+		if op.Path == nil {
+			return errors.New("invalid JSON patch document: operation is missing a path")
+		}
+	}
+
+	return nil
+}
+
+// Ensures a JSON Merge Patch document is a JSON object
+func validateMergePatch(doc []byte) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return fmt.Errorf("invalid JSON merge patch document: %w", err)
+	}
+
+	// A null document unmarshals without error, but would replace all metadata
+	if fields == nil {
+		return errors.New("invalid JSON merge patch document: expecting a JSON object")
+	}
+
+	return nil
+}
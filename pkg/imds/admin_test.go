@@ -0,0 +1,493 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
+	"github.com/purpleclay/imds-mock/pkg/imds/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, http.NoBody)
+	h.ServeHTTP(w, req)
+
+	return w
+}
+
+func patchMetadata(t *testing.T, m *imds.Mock, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPatch, "/metadata", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	m.AdminRouter().ServeHTTP(w, req)
+
+	return w
+}
+
+func spotInterruption(t *testing.T, m *imds.Mock, method, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, "/spot/interruption", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	m.AdminRouter().ServeHTTP(w, req)
+
+	return w
+}
+
+func TestAdminGetMetadata(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	w := get(t, m.AdminRouter(), "/metadata")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Result().Header["Content-Type"][0])
+	assert.Equal(t, "i-0decb1524582da041", gjson.Get(w.Body.String(), "instance-id").String())
+}
+
+func TestAdminJSONPatch(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	// Ensure categories are cached before patching
+	require.Equal(t, "imds-mock-ec2", get(t, m.Router(), "/latest/meta-data/tags/instance/Name").Body.String())
+	require.Equal(t, "Name", get(t, m.Router(), "/latest/meta-data/tags/instance").Body.String())
+	require.Equal(t, "10.0.1.100", get(t, m.Router(), "/latest/meta-data/local-ipv4").Body.String())
+
+	w := patchMetadata(t, m, imds.JSONPatchContentType, `[
+	{"op": "replace", "path": "/tags/instance/Name", "value": "patched"},
+	{"op": "add", "path": "/tags/instance/Environment", "value": "dev"},
+	{"op": "replace", "path": "/local-ipv4", "value": "10.0.1.200"}
+]`)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, "patched", get(t, m.Router(), "/latest/meta-data/tags/instance/Name").Body.String())
+	assert.Equal(t, "Name\nEnvironment", get(t, m.Router(), "/latest/meta-data/tags/instance").Body.String())
+	assert.Equal(t, "10.0.1.200", get(t, m.Router(), "/latest/meta-data/local-ipv4").Body.String())
+}
+
+func TestAdminMergePatch(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	require.Equal(t, "us-east-1", get(t, m.Router(), "/latest/meta-data/placement/region").Body.String())
+	require.Equal(t, http.StatusOK, get(t, m.Router(), "/latest/meta-data/profile").Code)
+
+	w := patchMetadata(t, m, imds.MergePatchContentType, `{
+	"placement": {"region": "eu-west-2"},
+	"profile": null
+}`)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, "eu-west-2", get(t, m.Router(), "/latest/meta-data/placement/region").Body.String())
+	assert.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/profile").Code)
+	assert.NotContains(t, get(t, m.Router(), "/latest/meta-data").Body.String(), "profile")
+}
+
+func TestAdminPatchErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+	}{
+		{
+			name:        "UnsupportedContentType",
+			contentType: "application/json",
+			body:        `{}`,
+			code:        http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "MalformedJSONPatch",
+			contentType: imds.JSONPatchContentType,
+			body:        `[`,
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:        "JSONPatchMissingPath",
+			contentType: imds.JSONPatchContentType,
+			body:        `[{"op": "remove"}]`,
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:        "MergePatchNotAnObject",
+			contentType: imds.MergePatchContentType,
+			body:        `["invalid"]`,
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:        "MergePatchNull",
+			contentType: imds.MergePatchContentType,
+			body:        `null`,
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:        "CannotApplyPatch",
+			contentType: imds.JSONPatchContentType,
+			body:        `[{"op": "remove", "path": "/unknown"}]`,
+			code:        http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := imds.New(testOptions)
+			require.NoError(t, err)
+
+			w := patchMetadata(t, m, tt.contentType, tt.body)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Contains(t, w.Body.String(), `"error"`)
+		})
+	}
+}
+
+func TestAdminSpotInterruption(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotNoNotice = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	require.Equal(t, "spot", get(t, m.Router(), "/latest/meta-data/instance-life-cycle").Body.String())
+	require.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/spot/instance-action").Code)
+
+	w := spotInterruption(t, m, http.MethodPost, `{"action": "stop"}`)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	action := get(t, m.Router(), "/latest/meta-data/spot/instance-action")
+	require.Equal(t, http.StatusOK, action.Code)
+	assert.Equal(t, "stop", gjson.Get(action.Body.String(), "action").String())
+}
+
+func TestAdminSpotInterruptionDefaultsToTerminate(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	w := spotInterruption(t, m, http.MethodPost, "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, "spot", get(t, m.Router(), "/latest/meta-data/instance-life-cycle").Body.String())
+	action := get(t, m.Router(), "/latest/meta-data/spot/instance-action")
+	assert.Equal(t, "terminate", gjson.Get(action.Body.String(), "action").String())
+}
+
+func TestAdminSpotInterruptionUnsupportedAction(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	w := spotInterruption(t, m, http.MethodPost, `{"action": "reboot"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "reboot is not a supported spot action")
+}
+
+func TestAdminSpotWithdrawInterruption(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, get(t, m.Router(), "/latest/meta-data/spot/instance-action").Code)
+
+	w := spotInterruption(t, m, http.MethodDelete, "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/spot/instance-action").Code)
+	assert.Equal(t, "spot", get(t, m.Router(), "/latest/meta-data/instance-life-cycle").Body.String())
+}
+
+func TestAdminSpotRebalance(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotNoNotice = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	require.Equal(t, "maintenance/", get(t, m.Router(), "/latest/meta-data/events").Body.String())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/spot/rebalance", http.NoBody)
+	m.AdminRouter().ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, "maintenance/\nrecommendations/", get(t, m.Router(), "/latest/meta-data/events").Body.String())
+	assert.Equal(t, http.StatusOK, get(t, m.Router(), "/latest/meta-data/events/recommendations/rebalance").Code)
+	assert.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/spot/instance-action").Code)
+}
+
+func TestAdminJournal(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	get(t, m.Router(), "/latest/meta-data/instance-id")
+	get(t, m.Router(), "/latest/meta-data/unknown")
+
+	w := get(t, m.AdminRouter(), "/journal")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	entries := gjson.Get(w.Body.String(), "#.path").Array()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "/latest/meta-data/instance-id", entries[0].String())
+	assert.Equal(t, "/latest/meta-data/unknown", entries[1].String())
+	assert.Equal(t, `[200,404]`, gjson.Get(w.Body.String(), "#.status").Raw)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/journal", http.NoBody)
+	w = httptest.NewRecorder()
+	m.AdminRouter().ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, "[]", get(t, m.AdminRouter(), "/journal").Body.String())
+}
+
+func TestAdminAuditV1(t *testing.T) {
+	opts := testOptions
+	opts.AuditV1 = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	req.Header.Set("User-Agent", "curl/7.81.0")
+	m.Router().ServeHTTP(w, req)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/latest/meta-data/local-ipv4", http.NoBody)
+	req.Header.Set(middleware.V2TokenHeader, mustToken(t, m))
+	m.Router().ServeHTTP(w, req)
+
+	w = get(t, m.AdminRouter(), "/audit/v1")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.JSONEq(t, `{
+	"metadataNoToken": 1,
+	"requests": [
+		{"path": "/latest/meta-data/instance-id", "userAgent": "curl/7.81.0", "count": 1}
+	]
+}`, w.Body.String())
+}
+
+func TestAdminAuditV1NotEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		opts func(imds.Options) imds.Options
+	}{
+		{
+			name: "Disabled",
+			opts: func(o imds.Options) imds.Options { return o },
+		},
+		{
+			name: "IMDSv2Enforced",
+			opts: func(o imds.Options) imds.Options {
+				o.AuditV1 = true
+				o.IMDSv2 = true
+				return o
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := imds.New(tt.opts(testOptions))
+			require.NoError(t, err)
+
+			w := get(t, m.AdminRouter(), "/audit/v1")
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Nil(t, m.V1Audit())
+		})
+	}
+}
+
+func TestAdminMetrics(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	req.Header.Set(middleware.V2TokenHeader, mustToken(t, m))
+	m.Router().ServeHTTP(w, req)
+
+	get(t, m.Router(), "/latest/meta-data/instance-id")
+	require.Equal(t, http.StatusNoContent, spotInterruption(t, m, http.MethodPost, "").Code)
+
+	w = get(t, m.AdminRouter(), "/metrics")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	out := w.Body.String()
+	assert.Contains(t, out, `imds_mock_requests_total{auth="v1",path="meta-data/instance-id",status="200"} 1`)
+	assert.Contains(t, out, `imds_mock_requests_total{auth="v2",path="meta-data/instance-id",status="200"} 1`)
+	assert.Contains(t, out, `imds_mock_tokens_issued_total 1`)
+	assert.Contains(t, out, `imds_mock_cache_requests_total{result="hit"} 1`)
+	assert.Contains(t, out, `imds_mock_cache_requests_total{result="miss"} 1`)
+	assert.Contains(t, out, `imds_mock_events_total{event="spot-interruption"} 1`)
+}
+
+func TestAdminMetricsBoundedPaths(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		get(t, m.Router(), fmt.Sprintf("/latest/meta-data/unknown-%d", i))
+		get(t, m.Router(), fmt.Sprintf("/latest/meta-data/tags/instance/Missing%d", i))
+	}
+	get(t, m.Router(), "/latest/meta-data/tags/instance/Name")
+
+	out := get(t, m.AdminRouter(), "/metrics").Body.String()
+	assert.Contains(t, out, `imds_mock_requests_total{auth="v1",path="unmatched",status="404"} 10`)
+	assert.Contains(t, out, `imds_mock_requests_total{auth="v1",path="meta-data/tags/instance/{key}",status="200"} 1`)
+	assert.NotContains(t, out, "unknown-")
+	assert.NotContains(t, out, "Missing")
+}
+
+func TestAdminUsage(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/tags/instance/Name", http.NoBody)
+	req.Header.Set(middleware.V2TokenHeader, mustToken(t, m))
+	m.Router().ServeHTTP(w, req)
+
+	get(t, m.Router(), "/latest/meta-data/tags/instance/Name")
+
+	w = get(t, m.AdminRouter(), "/usage")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.JSONEq(t, `{
+	"categories": [
+		{"category": "meta-data/tags/instance/{key}", "count": 2, "v1": 1, "v2": 1},
+		{"category": "api/token", "count": 1, "v1": 1, "v2": 0}
+	]
+}`, w.Body.String())
+}
+
+func adminRequest(t *testing.T, m *imds.Mock, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, http.NoBody)
+	m.AdminRouter().ServeHTTP(w, req)
+
+	return w
+}
+
+func getWithToken(t *testing.T, m *imds.Mock, tkn string) int {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	req.Header.Set(middleware.V2TokenHeader, tkn)
+	m.Router().ServeHTTP(w, req)
+
+	return w.Code
+}
+
+func TestAdminTokens(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	tkn := mustToken(t, m)
+
+	w := get(t, m.AdminRouter(), "/tokens")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	tokens := gjson.Parse(w.Body.String()).Array()
+	require.Len(t, tokens, 1)
+	assert.Equal(t, token.ID(tkn), tokens[0].Get("id").String())
+	assert.InDelta(t, 10, tokens[0].Get("ttl").Int(), 1)
+	assert.NotContains(t, w.Body.String(), tkn)
+}
+
+func TestAdminRevokeToken(t *testing.T) {
+	opts := testOptions
+	opts.IMDSv2 = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	revoked := mustToken(t, m)
+	kept := mustToken(t, m)
+
+	w := adminRequest(t, m, http.MethodDelete, "/tokens/"+token.ID(revoked))
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, getWithToken(t, m, revoked))
+	assert.Equal(t, http.StatusOK, getWithToken(t, m, kept))
+	assert.Equal(t, `[false,true]`, gjson.Get(get(t, m.AdminRouter(), "/journal").Body.String(), "#(tokenProvided==true)#.tokenValid").Raw)
+
+	w = adminRequest(t, m, http.MethodDelete, "/tokens/"+token.ID(revoked))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"no active session token with id `+token.ID(revoked)+`"}`, w.Body.String())
+}
+
+func TestAdminRevokeAllTokens(t *testing.T) {
+	opts := testOptions
+	opts.IMDSv2 = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	tkn := mustToken(t, m)
+	mustToken(t, m)
+
+	require.Equal(t, http.StatusNoContent, adminRequest(t, m, http.MethodDelete, "/tokens").Code)
+
+	assert.Equal(t, "[]", get(t, m.AdminRouter(), "/tokens").Body.String())
+	assert.Equal(t, http.StatusUnauthorized, getWithToken(t, m, tkn))
+}
+
+func TestAdminReboot(t *testing.T) {
+	opts := testOptions
+	opts.IMDSv2 = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	tkn := mustToken(t, m)
+	require.Equal(t, http.StatusOK, getWithToken(t, m, tkn))
+
+	require.Equal(t, http.StatusNoContent, adminRequest(t, m, http.MethodPost, "/reboot").Code)
+	assert.Equal(t, http.StatusUnauthorized, getWithToken(t, m, tkn))
+
+	// Clients recover by requesting a new session token
+	assert.Equal(t, http.StatusOK, getWithToken(t, m, mustToken(t, m)))
+	assert.Contains(t, get(t, m.AdminRouter(), "/metrics").Body.String(), `imds_mock_events_total{event="reboot"} 1`)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package audit
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Placeholders used when normalising category paths that contain
+// instance specific values
+const (
+	MACPlaceholder  = "{mac}"
+	RolePlaceholder = "{role}"
+	TagPlaceholder  = "{key}"
+)
+
+var macAddress = regexp.MustCompile(`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`)
+
+// Categories whose immediate child is an instance specific value
+var namedCategories = map[string]string{
+	"meta-data/iam/security-credentials": RolePlaceholder,
+	"meta-data/tags/instance":            TagPlaceholder,
+}
+
+// Usage aggregates the metadata categories accessed through the IMDS mock,
+// identifying which categories an application actually depends on
+type Usage struct {
+	counts map[string]*CategoryUsage
+	mu     sync.Mutex
+}
+
+// CategoryUsage defines how often a single category was accessed, and
+// whether a session token was used
+type CategoryUsage struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+	V1       int    `json:"v1"`
+	V2       int    `json:"v2"`
+}
+
+// UsageReport summarises all categories accessed through the IMDS mock
+type UsageReport struct {
+	// Categories contains every accessed category, ordered from most to
+	// least frequent
+	Categories []CategoryUsage `json:"categories"`
+}
+
+// NewUsage creates an aggregate of category usage without any recorded requests
+func NewUsage() *Usage {
+	return &Usage{counts: map[string]*CategoryUsage{}}
+}
+
+// Record a request against the given path, which is normalised into a category.
+// A V2 request provides a session token
+func (u *Usage) Record(path string, v2 bool) {
+	category := NormaliseCategory(path)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	usage, ok := u.counts[category]
+	if !ok {
+		usage = &CategoryUsage{Category: category}
+		u.counts[category] = usage
+	}
+
+	usage.Count++
+	if v2 {
+		usage.V2++
+	} else {
+		usage.V1++
+	}
+}
+
+// Report summarises all category usage recorded so far
+func (u *Usage) Report() UsageReport {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	categories := make([]CategoryUsage, 0, len(u.counts))
+	for _, usage := range u.counts {
+		categories = append(categories, *usage)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Count != categories[j].Count {
+			return categories[i].Count > categories[j].Count
+		}
+		return categories[i].Category < categories[j].Category
+	})
+
+	return UsageReport{Categories: categories}
+}
+
+// NormaliseCategory converts a request path into a category, relative to the
+// latest version of the IMDS. Instance specific values, such as MAC addresses,
+// IAM role names and instance tag keys are collapsed into placeholders
+//
+//	/latest/meta-data/network/interfaces/macs/06:e5:43:29:8c:08/mac
+//	meta-data/network/interfaces/macs/{mac}/mac
+func NormaliseCategory(path string) string {
+	path = strings.Trim(strings.TrimPrefix(path, "/latest"), "/")
+	if path == "" {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if macAddress.MatchString(segment) {
+			segments[i] = MACPlaceholder
+			continue
+		}
+
+		if i > 0 {
+			if placeholder, ok := namedCategories[strings.Join(segments[:i], "/")]; ok {
+				segments[i] = placeholder
+			}
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
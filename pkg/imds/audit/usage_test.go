@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageReport(t *testing.T) {
+	u := audit.NewUsage()
+	u.Record("/latest/meta-data/instance-id", false)
+	u.Record("/latest/meta-data/network/interfaces/macs/06:e5:43:29:8c:08/mac", true)
+	u.Record("/latest/meta-data/instance-id", true)
+	u.Record("/latest/meta-data/network/interfaces/macs/0e:1f:2a:3b:4c:5d/mac", true)
+	u.Record("/latest/api/token", false)
+
+	report := u.Report()
+
+	assert.Equal(t, []audit.CategoryUsage{
+		{Category: "meta-data/instance-id", Count: 2, V1: 1, V2: 1},
+		{Category: "meta-data/network/interfaces/macs/{mac}/mac", Count: 2, V1: 0, V2: 2},
+		{Category: "api/token", Count: 1, V1: 1, V2: 0},
+	}, report.Categories)
+}
+
+func TestUsageReportEmpty(t *testing.T) {
+	report := audit.NewUsage().Report()
+
+	assert.Empty(t, report.Categories)
+}
+
+func TestNormaliseCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		category string
+	}{
+		{
+			name:     "Root",
+			path:     "/latest/meta-data",
+			category: "meta-data",
+		},
+		{
+			name:     "TrailingSlash",
+			path:     "/latest/meta-data/placement/",
+			category: "meta-data/placement",
+		},
+		{
+			name:     "MACAddress",
+			path:     "/latest/meta-data/network/interfaces/macs/06:E5:43:29:8C:08/subnet-id",
+			category: "meta-data/network/interfaces/macs/{mac}/subnet-id",
+		},
+		{
+			name:     "IAMRole",
+			path:     "/latest/meta-data/iam/security-credentials/ssm-access",
+			category: "meta-data/iam/security-credentials/{role}",
+		},
+		{
+			name:     "IAMRoles",
+			path:     "/latest/meta-data/iam/security-credentials",
+			category: "meta-data/iam/security-credentials",
+		},
+		{
+			name:     "InstanceTag",
+			path:     "/latest/meta-data/tags/instance/Name",
+			category: "meta-data/tags/instance/{key}",
+		},
+		{
+			name:     "UserData",
+			path:     "/latest/user-data",
+			category: "user-data",
+		},
+		{
+			name:     "Dynamic",
+			path:     "/latest/dynamic/instance-identity/document",
+			category: "dynamic/instance-identity/document",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.category, audit.NormaliseCategory(tt.path))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package audit
+
+import (
+	"sort"
+	"sync"
+)
+
+// V1 counts requests made to the IMDS mock without a session token, mirroring
+// the MetadataNoToken CloudWatch metric of an EC2 instance. Requests are counted
+// separately by both path and user agent, identifying the code paths that will
+// break once IMDSv2 is enforced
+type V1 struct {
+	total  int
+	counts map[V1Request]int
+	mu     sync.Mutex
+}
+
+// V1Request identifies the source of an IMDSv1 request
+type V1Request struct {
+	Path      string `json:"path"`
+	UserAgent string `json:"userAgent"`
+}
+
+// V1Count defines the number of IMDSv1 requests made from a single source
+type V1Count struct {
+	V1Request
+	Count int `json:"count"`
+}
+
+// V1Report summarises all IMDSv1 requests made to the IMDS mock
+type V1Report struct {
+	// MetadataNoToken is the total number of IMDSv1 requests
+	MetadataNoToken int `json:"metadataNoToken"`
+
+	// Requests contains the number of IMDSv1 requests made from each source,
+	// ordered from most to least frequent
+	Requests []V1Count `json:"requests"`
+}
+
+// NewV1 creates an audit of IMDSv1 requests without any recorded requests
+func NewV1() *V1 {
+	return &V1{counts: map[V1Request]int{}}
+}
+
+// Record an IMDSv1 request against the given path and user agent
+func (a *V1) Record(path, userAgent string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.total++
+	a.counts[V1Request{Path: path, UserAgent: userAgent}]++
+}
+
+// Report summarises all IMDSv1 requests recorded so far
+func (a *V1) Report() V1Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	requests := make([]V1Count, 0, len(a.counts))
+	for req, count := range a.counts {
+		requests = append(requests, V1Count{V1Request: req, Count: count})
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].Count != requests[j].Count {
+			return requests[i].Count > requests[j].Count
+		}
+		if requests[i].Path != requests[j].Path {
+			return requests[i].Path < requests[j].Path
+		}
+		return requests[i].UserAgent < requests[j].UserAgent
+	})
+
+	return V1Report{
+		MetadataNoToken: a.total,
+		Requests:        requests,
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Report(t *testing.T) {
+	a := audit.NewV1()
+	a.Record("/latest/meta-data/instance-id", "aws-sdk-go/1.44.0")
+	a.Record("/latest/meta-data/tags/instance", "curl/7.81.0")
+	a.Record("/latest/meta-data/instance-id", "aws-sdk-go/1.44.0")
+	a.Record("/latest/meta-data/instance-id", "curl/7.81.0")
+
+	report := a.Report()
+
+	assert.Equal(t, 4, report.MetadataNoToken)
+	assert.Equal(t, []audit.V1Count{
+		{V1Request: audit.V1Request{Path: "/latest/meta-data/instance-id", UserAgent: "aws-sdk-go/1.44.0"}, Count: 2},
+		{V1Request: audit.V1Request{Path: "/latest/meta-data/instance-id", UserAgent: "curl/7.81.0"}, Count: 1},
+		{V1Request: audit.V1Request{Path: "/latest/meta-data/tags/instance", UserAgent: "curl/7.81.0"}, Count: 1},
+	}, report.Requests)
+}
+
+func TestV1ReportEmpty(t *testing.T) {
+	report := audit.NewV1().Report()
+
+	assert.Equal(t, 0, report.MetadataNoToken)
+	assert.Empty(t, report.Requests)
+}
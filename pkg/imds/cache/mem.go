@@ -22,45 +22,74 @@ SOFTWARE.
 
 package cache
 
-import "sync"
+import (
+	"sync"
+)
 
-// MemCache defines a lightweight in-memory cache that is thread safe
+// Versioned defines a source of cached data that tracks every change made to it
+// using a generation. The generation must change whenever the data does
+type Versioned interface {
+	Generation() uint64
+}
+
+// MemCache defines a lightweight in-memory cache that is thread safe. Every item
+// is cached against a generation of its source. Once the source changes, all
+// items cached against an older generation are discarded, ensuring a stale
+// item is never returned
 type MemCache struct {
-	items map[string]string
-	mu    sync.RWMutex
+	source     Versioned
+	generation uint64
+	items      map[string]string
+	mu         sync.Mutex
 }
 
-// New will generate an return a new empty in-memory cache
-func New() *MemCache {
+// New will generate an return a new empty in-memory cache for the given source
+func New(source Versioned) *MemCache {
 	return &MemCache{
-		items: map[string]string{},
+		source:     source,
+		generation: source.Generation(),
+		items:      map[string]string{},
 	}
 }
 
-// Set a value within the cache using the given cache key. If the value already
-// exists, it will be overwritten
-func (c *MemCache) Set(key string, value string) {
+// Generation returns the current generation of the cached source. It should be
+// captured before reading from the source, and used when setting any value
+// derived from it
+func (c *MemCache) Generation() uint64 {
+	return c.source.Generation()
+}
+
+// Set a value within the cache using the given cache key, providing the generation
+// of the source it was derived from. If the source has since changed, the value will
+// be ignored. If the value already exists, it will be overwritten
+func (c *MemCache) Set(key string, value string, generation uint64) {
 	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sync()
+	if generation != c.generation {
+		return
+	}
 	c.items[key] = value
-	c.mu.Unlock()
 }
 
 // Get returns an item from the cache using the given cache key. A flag is also returned
-// indicating whether the item exists. If no item exists, an empty string will be returned
+// indicating whether the item exists. If no item exists, or the source has changed since
+// it was cached, an empty string will be returned
 func (c *MemCache) Get(key string) (string, bool) {
-	c.mu.RLock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sync()
 	item, exists := c.items[key]
-	c.mu.RUnlock()
 
 	return item, exists
 }
 
-// Remove an item from the cache using the given cache key. Nothing will happen if no
-// item exists
-func (c *MemCache) Remove(keys ...string) {
-	c.mu.Lock()
-	for _, key := range keys {
-		delete(c.items, key)
+// Discards all cached items if the source has changed
+func (c *MemCache) sync() {
+	if generation := c.source.Generation(); generation != c.generation {
+		c.items = map[string]string{}
+		c.generation = generation
 	}
-	c.mu.Unlock()
 }
@@ -23,20 +23,45 @@ SOFTWARE.
 package cache
 
 import (
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// Simulates a source that is changed by incrementing its generation
+type source struct {
+	generation uint64
+}
+
+func (s *source) Generation() uint64 {
+	return atomic.LoadUint64(&s.generation)
+}
+
+func (s *source) change() {
+	atomic.AddUint64(&s.generation, 1)
+}
+
 func TestSet(t *testing.T) {
-	memc := New()
-	memc.Set("testing", "123")
+	memc := New(&source{})
+	memc.Set("testing", "123", memc.Generation())
 
 	assert.Len(t, memc.items, 1)
 }
 
+func TestSetStaleGeneration(t *testing.T) {
+	src := &source{}
+	memc := New(src)
+
+	generation := memc.Generation()
+	src.change()
+	memc.Set("testing", "123", generation)
+
+	assert.Len(t, memc.items, 0)
+}
+
 func TestGet(t *testing.T) {
-	memc := New()
+	memc := New(&source{})
 	memc.items["testing"] = "123"
 
 	item, exists := memc.Get("testing")
@@ -46,7 +71,7 @@ func TestGet(t *testing.T) {
 }
 
 func TestGetNotExists(t *testing.T) {
-	memc := New()
+	memc := New(&source{})
 
 	item, exists := memc.Get("testing")
 
@@ -54,12 +79,31 @@ func TestGetNotExists(t *testing.T) {
 	assert.Equal(t, "", item)
 }
 
-func TestRemove(t *testing.T) {
-	memc := New()
-	memc.items["testing1"] = "123"
-	memc.items["testing2"] = "456"
+func TestGetSourceChanged(t *testing.T) {
+	src := &source{}
+	memc := New(src)
+	memc.Set("/latest/meta-data/instance-life-cycle", "on-demand", memc.Generation())
+	memc.Set("/latest/meta-data/placement/region", "eu-west-2", memc.Generation())
+
+	src.change()
 
-	memc.Remove("testing1", "testing2")
+	item, exists := memc.Get("/latest/meta-data/instance-life-cycle")
 
+	assert.False(t, exists)
+	assert.Equal(t, "", item)
 	assert.Len(t, memc.items, 0)
 }
+
+func TestGetAfterSourceChanged(t *testing.T) {
+	src := &source{}
+	memc := New(src)
+	memc.Set("/latest/meta-data/instance-life-cycle", "on-demand", memc.Generation())
+
+	src.change()
+	memc.Set("/latest/meta-data/instance-life-cycle", "spot", memc.Generation())
+
+	item, exists := memc.Get("/latest/meta-data/instance-life-cycle")
+
+	assert.True(t, exists)
+	assert.Equal(t, "spot", item)
+}
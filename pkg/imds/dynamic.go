@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/identity"
+)
+
+const (
+	dynamicCategories = "instance-identity/"
+
+	instanceIdentityCategories = `document
+pkcs7
+rsa2048
+signature`
+)
+
+// Defers generation of a key pair until an instance identity document is first
+// signed, as generating an RSA key can be slow
+type lazySigner struct {
+	once   sync.Once
+	signer *identity.Signer
+	err    error
+}
+
+func newLazySigner(opts Options) (*lazySigner, error) {
+	if len(opts.IdentityKey) == 0 && len(opts.IdentityCertificate) == 0 {
+		return &lazySigner{}, nil
+	}
+
+	// Ensure any provided key pair is valid at startup
+	signer, err := identity.LoadSigner(opts.IdentityKey, opts.IdentityCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lazySigner{signer: signer}, nil
+}
+
+func (s *lazySigner) get() (*identity.Signer, error) {
+	s.once.Do(func() {
+		if s.signer == nil {
+			s.signer, s.err = identity.NewSigner()
+		}
+	})
+
+	return s.signer, s.err
+}
+
+func dynamicHandler(metadata *patchedJSON, signer *lazySigner, launched time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		category := strings.TrimSuffix(c.Param("category"), "/")
+
+		var out string
+		switch category {
+		case "":
+			out = dynamicCategories
+		case "/instance-identity":
+			out = instanceIdentityCategories
+		case "/instance-identity/document":
+			out = string(identity.NewDocument(metadata.Bytes(), launched).Bytes())
+		case "/instance-identity/signature", "/instance-identity/pkcs7", "/instance-identity/rsa2048":
+			s, err := signer.get()
+			if err != nil {
+				c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+
+			doc := identity.NewDocument(metadata.Bytes(), launched).Bytes()
+			if category == "/instance-identity/signature" {
+				out, err = s.Signature(doc)
+			} else {
+				out, err = s.PKCS7(doc)
+			}
+
+			if err != nil {
+				c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+		default:
+			c.Writer.Header().Add("Content-Type", "text/html")
+			c.String(http.StatusNotFound, notFound)
+			return
+		}
+
+		c.Writer.Header().Add("Content-Type", "text/plain")
+		c.String(http.StatusOK, out)
+	}
+}
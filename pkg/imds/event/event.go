@@ -23,6 +23,7 @@ SOFTWARE.
 package event
 
 import (
+	"sync"
 	"time"
 )
 
@@ -39,3 +40,58 @@ func Once(delay time.Duration, fn func()) {
 		fn()
 	}()
 }
+
+// Scheduler will execute user defined functions after a given duration only once,
+// tracking any pending execution so that they can be cancelled together
+type Scheduler struct {
+	timers  map[*time.Timer]struct{}
+	stopped bool
+	mu      sync.Mutex
+}
+
+// NewScheduler creates a scheduler without any pending functions
+func NewScheduler() *Scheduler {
+	return &Scheduler{timers: map[*time.Timer]struct{}{}}
+}
+
+// Once will execute a user defined function after a given duration only once. If
+// the scheduler has been stopped, the function will never be executed
+func (s *Scheduler) Once(delay time.Duration, fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		s.mu.Lock()
+		delete(s.timers, timer)
+		s.mu.Unlock()
+
+		fn()
+	})
+	s.timers[timer] = struct{}{}
+}
+
+// Pending returns the number of functions waiting to be executed
+func (s *Scheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.timers)
+}
+
+// Stop cancels all pending functions and prevents any further functions
+// from being scheduled. Any function already executing will not be interrupted
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, timer)
+	}
+}
@@ -47,3 +47,30 @@ func TestOnce(t *testing.T) {
 	// If event fired successfully, the time will not be the default zero time
 	assert.NotEqual(t, 1, actual.Year())
 }
+
+func TestSchedulerOnce(t *testing.T) {
+	fired := make(chan struct{})
+
+	s := event.NewScheduler()
+	s.Once(10*time.Millisecond, func() { close(fired) })
+	assert.Equal(t, 1, s.Pending())
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		assert.Fail(t, "scheduled event did not fire")
+	}
+	assert.Eventually(t, func() bool { return s.Pending() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestSchedulerStop(t *testing.T) {
+	fired := make(chan struct{}, 2)
+
+	s := event.NewScheduler()
+	s.Once(10*time.Millisecond, func() { fired <- struct{}{} })
+	s.Stop()
+	s.Once(10*time.Millisecond, func() { fired <- struct{}{} })
+
+	assert.Equal(t, 0, s.Pending())
+	assert.Never(t, func() bool { return len(fired) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+}
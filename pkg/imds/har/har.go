@@ -0,0 +1,282 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Version of the HAR specification supported by the archive
+const Version = "1.2"
+
+// DefaultCapacity defines the default number of entries retained by an archive
+const DefaultCapacity = 10000
+
+// Base64Encoding identifies content that has been base64 encoded, as it
+// cannot be represented as UTF-8 text
+const Base64Encoding = "base64"
+
+// Name of the application that creates the archive
+const creatorName = "imds-mock"
+
+// HAR is the root object of an HTTP Archive
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log contains all exported traffic
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+	Comment string  `json:"comment,omitempty"`
+}
+
+// Creator contains information about the application that created the archive
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry contains a single request and response pair
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"`
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           Cache     `json:"cache"`
+	Timings         Timings   `json:"timings"`
+}
+
+// Request contains detailed information about a performed request
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// Response contains detailed information about the response to a request
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Cookie contains a cookie used within a request or response. The IMDS
+// doesn't use cookies, but the HAR specification requires them
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NameValue contains either a header or query string parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Content contains details about the body of a response. Any body that is
+// not valid UTF-8, such as gzip compressed user data, is base64 encoded
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// NewContent creates the content of a response body, encoding it as base64
+// if it cannot be represented as UTF-8 text
+func NewContent(body []byte, mimeType string) Content {
+	content := Content{
+		Size:     len(body),
+		MimeType: mimeType,
+	}
+
+	if utf8.Valid(body) {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = Base64Encoding
+	}
+
+	return content
+}
+
+// Cache contains information about any cache usage. The IMDS mock caches
+// responses internally, so it is always empty
+type Cache struct{}
+
+// Timings contains a breakdown of the time taken to complete a request,
+// in milliseconds
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Archive records request and response pairs in memory, ready for exporting
+// as an HTTP Archive. It is bounded, and once full, the oldest entry is discarded
+// for every new entry recorded. It is safe for concurrent use
+type Archive struct {
+	entries   []Entry
+	next      int
+	full      bool
+	capacity  int
+	discarded int
+	mu        sync.Mutex
+}
+
+// New creates an empty archive that retains up to the given number of entries.
+// If the capacity is not positive, the DefaultCapacity is used
+func New(capacity int) *Archive {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+
+	return &Archive{
+		entries:  make([]Entry, capacity),
+		capacity: capacity,
+	}
+}
+
+// Record an entry within the archive
+func (a *Archive) Record(entry Entry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.full {
+		a.discarded++
+	}
+
+	a.entries[a.next] = entry
+	a.next = (a.next + 1) % a.capacity
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// Entries returns a copy of all entries in the order they were recorded
+func (a *Archive) Entries() []Entry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.ordered()
+}
+
+// Discarded returns the number of entries discarded once the archive was full
+func (a *Archive) Discarded() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.discarded
+}
+
+func (a *Archive) ordered() []Entry {
+	if !a.full {
+		return append([]Entry{}, a.entries[:a.next]...)
+	}
+
+	return append(append([]Entry{}, a.entries[a.next:]...), a.entries[:a.next]...)
+}
+
+// Write the archive as an indented HAR document
+func (a *Archive) Write(w io.Writer) error {
+	a.mu.Lock()
+	log := Log{
+		Version: Version,
+		Creator: Creator{Name: creatorName, Version: creatorVersion()},
+		Entries: a.ordered(),
+	}
+	if a.discarded > 0 {
+		log.Comment = fmt.Sprintf("%d earlier entries were discarded, only the most recent %d are retained", a.discarded, a.capacity)
+	}
+	a.mu.Unlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(HAR{Log: log})
+}
+
+// WriteFile writes the archive as a HAR document to the named file, replacing
+// any existing file
+func (a *Archive) WriteFile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func creatorVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
+
+// NameValues converts either HTTP headers or query string parameters into
+// HAR name value pairs, sorted by name
+func NameValues(values map[string][]string) []NameValue {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := []NameValue{}
+	for _, name := range names {
+		for _, value := range values[name] {
+			pairs = append(pairs, NameValue{Name: name, Value: value})
+		}
+	}
+
+	return pairs
+}
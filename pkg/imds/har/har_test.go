@@ -0,0 +1,154 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package har_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/har"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestArchive(t *testing.T) {
+	archive := har.New(har.DefaultCapacity)
+	archive.Record(har.Entry{Request: har.Request{URL: "http://localhost:1338/latest/api/token"}})
+	archive.Record(har.Entry{Request: har.Request{URL: "http://localhost:1338/latest/meta-data"}})
+
+	entries := archive.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "http://localhost:1338/latest/api/token", entries[0].Request.URL)
+	assert.Equal(t, "http://localhost:1338/latest/meta-data", entries[1].Request.URL)
+}
+
+func TestArchiveBounded(t *testing.T) {
+	archive := har.New(2)
+	for _, method := range []string{"PUT", "GET", "POST"} {
+		archive.Record(har.Entry{Request: har.Request{Method: method}})
+	}
+
+	entries := archive.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "GET", entries[0].Request.Method)
+	assert.Equal(t, "POST", entries[1].Request.Method)
+	assert.Equal(t, 1, archive.Discarded())
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.Write(&buf))
+	assert.Equal(t, "1 earlier entries were discarded, only the most recent 2 are retained", gjson.Get(buf.String(), "log.comment").String())
+}
+
+func TestArchiveDefaultCapacity(t *testing.T) {
+	archive := har.New(0)
+	for i := 0; i < har.DefaultCapacity; i++ {
+		archive.Record(har.Entry{})
+	}
+
+	assert.Len(t, archive.Entries(), har.DefaultCapacity)
+	assert.Zero(t, archive.Discarded())
+}
+
+func TestNewContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     []byte
+		expected har.Content
+	}{
+		{
+			name:     "Text",
+			body:     []byte("i-0123456789abcdef0"),
+			expected: har.Content{Size: 19, MimeType: "text/plain", Text: "i-0123456789abcdef0"},
+		},
+		{
+			name:     "Binary",
+			body:     []byte{0x1f, 0x8b, 0x08, 0x00, 0xff},
+			expected: har.Content{Size: 5, MimeType: "text/plain", Text: "H4sIAP8=", Encoding: har.Base64Encoding},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, har.NewContent(tt.body, "text/plain"))
+		})
+	}
+}
+
+func TestArchiveWrite(t *testing.T) {
+	started := time.Date(2022, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	archive := har.New(har.DefaultCapacity)
+	archive.Record(har.Entry{
+		StartedDateTime: started,
+		Time:            1.5,
+		Request:         har.Request{Method: "GET", URL: "http://localhost:1338/latest/meta-data"},
+		Response:        har.Response{Status: 200},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.Write(&buf))
+
+	out := buf.String()
+	assert.Equal(t, har.Version, gjson.Get(out, "log.version").String())
+	assert.Equal(t, "imds-mock", gjson.Get(out, "log.creator.name").String())
+	assert.NotEmpty(t, gjson.Get(out, "log.creator.version").String())
+	assert.Equal(t, "2022-10-18T12:00:00Z", gjson.Get(out, "log.entries.0.startedDateTime").String())
+	assert.Equal(t, 1.5, gjson.Get(out, "log.entries.0.time").Float())
+	assert.Equal(t, "GET", gjson.Get(out, "log.entries.0.request.method").String())
+	assert.Equal(t, int64(200), gjson.Get(out, "log.entries.0.response.status").Int())
+}
+
+func TestArchiveWriteEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, har.New(har.DefaultCapacity).Write(&buf))
+
+	assert.Equal(t, "[]", gjson.Get(buf.String(), "log.entries").Raw)
+}
+
+func TestArchiveWriteFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "imds-mock.har")
+
+	archive := har.New(har.DefaultCapacity)
+	archive.Record(har.Entry{Request: har.Request{Method: "PUT"}})
+	require.NoError(t, archive.WriteFile(file))
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "PUT", gjson.GetBytes(data, "log.entries.0.request.method").String())
+}
+
+func TestNameValues(t *testing.T) {
+	pairs := har.NameValues(map[string][]string{
+		"User-Agent": {"curl/7.81.0"},
+		"Accept":     {"text/plain", "application/json"},
+	})
+
+	assert.Equal(t, []har.NameValue{
+		{Name: "Accept", Value: "text/plain"},
+		{Name: "Accept", Value: "application/json"},
+		{Name: "User-Agent", Value: "curl/7.81.0"},
+	}, pairs)
+}
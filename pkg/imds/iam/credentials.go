@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package iam
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"math/big"
+	"time"
+)
+
+const (
+	// DefaultRole defines the name of the IAM role attached to the instance when
+	// one is not provided
+	DefaultRole = "ssm-access"
+
+	// DefaultCredentialsTTL defines the default lifetime of any generated credentials,
+	// matching the six hour expiry of credentials issued to an EC2 instance
+	DefaultCredentialsTTL = 6 * time.Hour
+
+	// New credentials are made available at least five minutes before the
+	// expiration of the existing credentials
+	refreshWindow = 5 * time.Minute
+
+	// Character sets used when generating identifiers
+	upperAlphaNumeric = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	secretCharacters  = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+)
+
+// Info contains details about the instance profile associated with an instance
+type Info struct {
+	Code               string `json:"Code"`
+	LastUpdated        string `json:"LastUpdated"`
+	InstanceProfileArn string `json:"InstanceProfileArn"`
+	InstanceProfileID  string `json:"InstanceProfileId"`
+}
+
+// Credentials contains a set of temporary security credentials associated with
+// the IAM role of an instance profile
+type Credentials struct {
+	Code            string `json:"Code"`
+	LastUpdated     string `json:"LastUpdated"`
+	Type            string `json:"Type"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+	Expiration      string `json:"Expiration"`
+}
+
+// NewInfo generates details of an instance profile using the provided ARN
+func NewInfo(instanceProfileArn string) Info {
+	return Info{
+		Code:               "Success",
+		LastUpdated:        time.Now().UTC().Format(time.RFC3339),
+		InstanceProfileArn: instanceProfileArn,
+		InstanceProfileID:  "AIPA" + random(upperAlphaNumeric, 17),
+	}
+}
+
+// NewCredentials generates a new set of temporary security credentials that
+// will expire after the provided TTL
+func NewCredentials(ttl time.Duration) Credentials {
+	now := time.Now().UTC()
+
+	token := make([]byte, 256)
+	rand.Read(token)
+
+	return Credentials{
+		Code:            "Success",
+		LastUpdated:     now.Format(time.RFC3339),
+		Type:            "AWS-HMAC",
+		AccessKeyID:     "ASIA" + random(upperAlphaNumeric, 16),
+		SecretAccessKey: random(secretCharacters, 40),
+		Token:           base64.StdEncoding.EncodeToString(token),
+		Expiration:      now.Add(ttl).Format(time.RFC3339),
+	}
+}
+
+// RefreshInterval calculates when a new set of credentials should be issued,
+// ensuring they are always available ahead of the existing credentials expiring
+func RefreshInterval(ttl time.Duration) time.Duration {
+	refresh := ttl - refreshWindow
+	if refresh < ttl/2 {
+		refresh = ttl / 2
+	}
+
+	return refresh
+}
+
+func random(charset string, n int) string {
+	max := big.NewInt(int64(len(charset)))
+
+	out := make([]byte, n)
+	for i := range out {
+		idx, _ := rand.Int(rand.Reader, max)
+		out[i] = charset[idx.Int64()]
+	}
+
+	return string(out)
+}
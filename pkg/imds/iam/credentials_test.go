@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package iam_test
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInfo(t *testing.T) {
+	info := iam.NewInfo("arn:aws:iam::112233445566:instance-profile/testing")
+
+	assert.Equal(t, "Success", info.Code)
+	assert.Equal(t, "arn:aws:iam::112233445566:instance-profile/testing", info.InstanceProfileArn)
+	assert.Regexp(t, "^AIPA[A-Z2-7]{17}$", info.InstanceProfileID)
+
+	updated, err := time.Parse(time.RFC3339, info.LastUpdated)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), updated, 1*time.Second)
+}
+
+func TestNewCredentials(t *testing.T) {
+	creds := iam.NewCredentials(1 * time.Hour)
+
+	assert.Equal(t, "Success", creds.Code)
+	assert.Equal(t, "AWS-HMAC", creds.Type)
+	assert.Regexp(t, "^ASIA[A-Z2-7]{16}$", creds.AccessKeyID)
+	assert.Len(t, creds.SecretAccessKey, 40)
+
+	_, err := base64.StdEncoding.DecodeString(creds.Token)
+	assert.NoError(t, err)
+
+	expiration, err := time.Parse(time.RFC3339, creds.Expiration)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(1*time.Hour), expiration, 1*time.Second)
+}
+
+func TestNewCredentials_Unique(t *testing.T) {
+	first := iam.NewCredentials(1 * time.Hour)
+	second := iam.NewCredentials(1 * time.Hour)
+
+	assert.NotEqual(t, first.AccessKeyID, second.AccessKeyID)
+	assert.NotEqual(t, first.SecretAccessKey, second.SecretAccessKey)
+	assert.NotEqual(t, first.Token, second.Token)
+}
+
+func TestRefreshInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "BeforeRefreshWindow",
+			ttl:      6 * time.Hour,
+			expected: 5*time.Hour + 55*time.Minute,
+		},
+		{
+			name:     "ShortLived",
+			ttl:      2 * time.Minute,
+			expected: 1 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, iam.RefreshInterval(tt.ttl))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package identity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// The version of the instance identity document currently served by EC2
+const documentVersion = "2017-09-30"
+
+// Document defines an instance identity document, which describes the
+// running EC2 instance and can be verified against its signature, see:
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/instance-identity-documents.html
+type Document struct {
+	AccountID               string   `json:"accountId"`
+	Architecture            string   `json:"architecture"`
+	AvailabilityZone        string   `json:"availabilityZone"`
+	BillingProducts         []string `json:"billingProducts"`
+	DevpayProductCodes      []string `json:"devpayProductCodes"`
+	MarketplaceProductCodes []string `json:"marketplaceProductCodes"`
+	ImageID                 string   `json:"imageId"`
+	InstanceID              string   `json:"instanceId"`
+	InstanceType            string   `json:"instanceType"`
+	KernelID                *string  `json:"kernelId"`
+	PendingTime             string   `json:"pendingTime"`
+	PrivateIP               string   `json:"privateIp"`
+	RamdiskID               *string  `json:"ramdiskId"`
+	Region                  string   `json:"region"`
+	Version                 string   `json:"version"`
+}
+
+// NewDocument derives an instance identity document from the instance metadata
+// served by the IMDS mock. The pending time denotes when the instance was launched
+func NewDocument(metadata []byte, pending time.Time) Document {
+	mac := gjson.GetBytes(metadata, "mac").String()
+
+	return Document{
+		AccountID:        gjson.GetBytes(metadata, "network.interfaces.macs."+gjson.Escape(mac)+".owner-id").String(),
+		Architecture:     "x86_64",
+		AvailabilityZone: gjson.GetBytes(metadata, "placement.availability-zone").String(),
+		ImageID:          gjson.GetBytes(metadata, "ami-id").String(),
+		InstanceID:       gjson.GetBytes(metadata, "instance-id").String(),
+		InstanceType:     gjson.GetBytes(metadata, "instance-type").String(),
+		PendingTime:      pending.UTC().Format(time.RFC3339),
+		PrivateIP:        gjson.GetBytes(metadata, "local-ipv4").String(),
+		Region:           gjson.GetBytes(metadata, "placement.region").String(),
+		Version:          documentVersion,
+	}
+}
+
+// Bytes returns the indented JSON representation of the document, exactly as
+// it would be served by the IMDS service
+func (d Document) Bytes() []byte {
+	out, _ := json.MarshalIndent(d, "", "  ")
+	return out
+}
@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package identity_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const metadata = `{
+	"ami-id": "ami-0e34bbddc66def5ac",
+	"instance-id": "i-0decb1524582da041",
+	"instance-type": "m4.xlarge",
+	"local-ipv4": "10.0.1.100",
+	"mac": "06:e5:43:29:8f:08",
+	"network": {
+		"interfaces": {
+			"macs": {
+				"06:e5:43:29:8f:08": {
+					"owner-id": "112233445566"
+				}
+			}
+		}
+	},
+	"placement": {
+		"availability-zone": "us-east-1a",
+		"region": "us-east-1"
+	}
+}`
+
+func TestNewDocument(t *testing.T) {
+	pending := time.Date(2022, time.August, 8, 4, 25, 36, 0, time.UTC)
+
+	doc := identity.NewDocument([]byte(metadata), pending)
+
+	assert.Equal(t, "112233445566", doc.AccountID)
+	assert.Equal(t, "x86_64", doc.Architecture)
+	assert.Equal(t, "us-east-1a", doc.AvailabilityZone)
+	assert.Equal(t, "ami-0e34bbddc66def5ac", doc.ImageID)
+	assert.Equal(t, "i-0decb1524582da041", doc.InstanceID)
+	assert.Equal(t, "m4.xlarge", doc.InstanceType)
+	assert.Equal(t, "2022-08-08T04:25:36Z", doc.PendingTime)
+	assert.Equal(t, "10.0.1.100", doc.PrivateIP)
+	assert.Equal(t, "us-east-1", doc.Region)
+	assert.Equal(t, "2017-09-30", doc.Version)
+}
+
+func TestDocumentBytes(t *testing.T) {
+	doc := identity.NewDocument([]byte(metadata), time.Now())
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(doc.Bytes(), &out))
+
+	assert.Nil(t, out["kernelId"])
+	assert.Nil(t, out["ramdiskId"])
+	assert.Nil(t, out["billingProducts"])
+	assert.Equal(t, "i-0decb1524582da041", out["instanceId"])
+}
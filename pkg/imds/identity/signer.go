@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package identity
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// Signer is used to sign an instance identity document, replicating the regional
+// signatures provided by AWS. Any signature can be verified using the public
+// certificate of the signer
+type Signer struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+// NewSigner generates a new RSA 2048 key pair along with a self-signed certificate
+// that can be used for signing an instance identity document
+func NewSigner() (*Signer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   "imds-mock",
+			Organization: []string{"imds-mock"},
+		},
+		NotBefore:             now.Add(-1 * time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{key: key, cert: cert}, nil
+}
+
+// LoadSigner creates a signer from an existing PEM encoded RSA private key and
+// its associated PEM encoded certificate
+func LoadSigner(keyPEM, certPEM []byte) (*Signer, error) {
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, errors.New("identity key is not PEM encoded")
+	}
+
+	key, err := parseRSAKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, errors.New("identity certificate is not PEM encoded")
+	}
+
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity certificate: %w", err)
+	}
+
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		return nil, errors.New("identity certificate does not match the identity key")
+	}
+
+	return &Signer{key: key, cert: cert}, nil
+}
+
+func parseRSAKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("identity key must be an RSA private key")
+	}
+
+	return rsaKey, nil
+}
+
+// CertificatePEM returns the PEM encoded public certificate of the signer. This
+// certificate should be used when verifying any signature
+func (s *Signer) CertificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.cert.Raw})
+}
+
+// KeyPEM returns the PEM encoded RSA private key of the signer
+func (s *Signer) KeyPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(s.key)})
+}
+
+// Signature generates a base64 encoded RSA SHA256 signature of the document
+func (s *Signer) Signature(doc []byte) (string, error) {
+	digest := sha256.Sum256(doc)
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return wrap(base64.StdEncoding.EncodeToString(sig)), nil
+}
+
+// PKCS7 generates a detached PKCS7 signature of the document. The signature is
+// base64 encoded without any PEM header or footer, replicating the IMDS service
+func (s *Signer) PKCS7(doc []byte) (string, error) {
+	sd, err := pkcs7.NewSignedData(doc)
+	if err != nil {
+		return "", err
+	}
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+
+	if err := sd.AddSigner(s.cert, s.key, pkcs7.SignerInfoConfig{}); err != nil {
+		return "", err
+	}
+	sd.Detach()
+
+	out, err := sd.Finish()
+	if err != nil {
+		return "", err
+	}
+
+	return wrap(base64.StdEncoding.EncodeToString(out)), nil
+}
+
+// Base64 encoded signatures are wrapped at 64 characters per line
+func wrap(in string) string {
+	var lines []string
+	for len(in) > 64 {
+		lines = append(lines, in[:64])
+		in = in[64:]
+	}
+	lines = append(lines, in)
+
+	return strings.Join(lines, "\n")
+}
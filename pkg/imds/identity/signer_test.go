@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package identity_test
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mozilla.org/pkcs7"
+)
+
+var document = []byte(`{"instanceId":"i-0decb1524582da041"}`)
+
+func parseCertificate(t *testing.T, certPEM []byte) *x509.Certificate {
+	t.Helper()
+
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func decodeWrapped(t *testing.T, in string) []byte {
+	t.Helper()
+
+	for _, line := range strings.Split(in, "\n") {
+		require.LessOrEqual(t, len(line), 64)
+	}
+
+	out, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(in, "\n", ""))
+	require.NoError(t, err)
+
+	return out
+}
+
+func TestSignature(t *testing.T) {
+	signer, err := identity.NewSigner()
+	require.NoError(t, err)
+
+	sig, err := signer.Signature(document)
+	require.NoError(t, err)
+
+	cert := parseCertificate(t, signer.CertificatePEM())
+	digest := sha256.Sum256(document)
+
+	err = rsa.VerifyPKCS1v15(cert.PublicKey.(*rsa.PublicKey), crypto.SHA256, digest[:], decodeWrapped(t, sig))
+	assert.NoError(t, err)
+}
+
+func TestPKCS7(t *testing.T) {
+	signer, err := identity.NewSigner()
+	require.NoError(t, err)
+
+	sig, err := signer.PKCS7(document)
+	require.NoError(t, err)
+
+	p7, err := pkcs7.Parse(decodeWrapped(t, sig))
+	require.NoError(t, err)
+
+	// Signature is detached and must be verified against the original document
+	assert.Empty(t, p7.Content)
+	p7.Content = document
+
+	require.NoError(t, p7.Verify())
+	assert.Equal(t, parseCertificate(t, signer.CertificatePEM()).Raw, p7.GetOnlySigner().Raw)
+}
+
+func TestLoadSigner(t *testing.T) {
+	generated, err := identity.NewSigner()
+	require.NoError(t, err)
+
+	signer, err := identity.LoadSigner(generated.KeyPEM(), generated.CertificatePEM())
+	require.NoError(t, err)
+
+	assert.Equal(t, generated.CertificatePEM(), signer.CertificatePEM())
+}
+
+func TestLoadSignerError(t *testing.T) {
+	first, err := identity.NewSigner()
+	require.NoError(t, err)
+
+	second, err := identity.NewSigner()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		key    []byte
+		cert   []byte
+		errMsg string
+	}{
+		{
+			name:   "KeyNotPEM",
+			key:    []byte("not pem"),
+			cert:   first.CertificatePEM(),
+			errMsg: "identity key is not PEM encoded",
+		},
+		{
+			name:   "CertificateNotPEM",
+			key:    first.KeyPEM(),
+			cert:   []byte("not pem"),
+			errMsg: "identity certificate is not PEM encoded",
+		},
+		{
+			name:   "MismatchedKeyPair",
+			key:    first.KeyPEM(),
+			cert:   second.CertificatePEM(),
+			errMsg: "identity certificate does not match the identity key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := identity.LoadSigner(tt.key, tt.cert)
+
+			require.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
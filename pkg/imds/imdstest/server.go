@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package imdstest provides utilities for testing code that depends on the
+// Amazon EC2 Instance Metadata Service (IMDS)
+package imdstest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+)
+
+// EndpointEnv defines the environment variable used by the AWS SDKs to
+// override the endpoint of the IMDS
+const EndpointEnv = "AWS_EC2_METADATA_SERVICE_ENDPOINT"
+
+// Server is an IMDS mock listening on a random port on the local loopback
+// interface, for use within tests
+type Server struct {
+	// URL of the IMDS mock, of the form http://ipaddr:port with no trailing slash
+	URL string
+
+	// Mock provides direct access to the underlying IMDS mock
+	Mock *imds.Mock
+
+	t testing.TB
+}
+
+// NewServer starts an IMDS mock configured using the provided options. The
+// EndpointEnv environment variable is set to the URL of the mock for the
+// duration of the test, and the mock is shutdown automatically once the
+// test completes, cancelling any scheduled events
+func NewServer(t testing.TB, opts imds.Options) *Server {
+	t.Helper()
+
+	// The lifecycle of the mock is managed by the test
+	opts.AutoStart = false
+
+	m, err := imds.New(opts)
+	if err != nil {
+		t.Fatalf("imdstest: failed to configure IMDS mock: %v", err)
+	}
+
+	srv := httptest.NewServer(m.Router())
+	t.Cleanup(func() {
+		m.Close()
+		srv.Close()
+	})
+	t.Setenv(EndpointEnv, srv.URL)
+
+	return &Server{
+		URL:  srv.URL,
+		Mock: m,
+		t:    t,
+	}
+}
+
+// Patch the instance metadata served by the mock, failing the test if
+// the patch cannot be applied
+func (s *Server) Patch(patcher patch.JSONPatcher) {
+	s.t.Helper()
+
+	if err := s.Mock.Patch(patcher); err != nil {
+		s.t.Fatalf("imdstest: failed to patch instance metadata: %v", err)
+	}
+}
+
+// JSONPatch patches the instance metadata served by the mock using a JSON
+// Patch document, see: https://www.rfc-editor.org/rfc/rfc6902
+func (s *Server) JSONPatch(doc string) {
+	s.t.Helper()
+	s.Patch(patch.JSON{Document: []byte(doc)})
+}
+
+// MergePatch patches the instance metadata served by the mock using a JSON
+// Merge Patch document, see: https://www.rfc-editor.org/rfc/rfc7396
+func (s *Server) MergePatch(doc string) {
+	s.t.Helper()
+	s.Patch(patch.Merge{Document: []byte(doc)})
+}
+
+// InterruptSpot raises a spot interruption notice with the given instance action,
+// failing the test if it cannot be raised
+func (s *Server) InterruptSpot(action patch.SpotInstanceAction) {
+	s.t.Helper()
+
+	if err := s.Mock.InterruptSpot(action); err != nil {
+		s.t.Fatalf("imdstest: failed to raise spot interruption notice: %v", err)
+	}
+}
+
+// RecommendSpotRebalance raises a rebalance recommendation, failing the test if
+// it cannot be raised
+func (s *Server) RecommendSpotRebalance() {
+	s.t.Helper()
+
+	if err := s.Mock.RecommendSpotRebalance(); err != nil {
+		s.t.Fatalf("imdstest: failed to raise rebalance recommendation: %v", err)
+	}
+}
+
+// WithdrawSpotInterruption withdraws any raised spot interruption notice, failing
+// the test if it cannot be withdrawn
+func (s *Server) WithdrawSpotInterruption() {
+	s.t.Helper()
+
+	if err := s.Mock.WithdrawSpotInterruption(); err != nil {
+		s.t.Fatalf("imdstest: failed to withdraw spot interruption notice: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imdstest_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/imdstest"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestNewServer(t *testing.T) {
+	srv := imdstest.NewServer(t, imds.DefaultOptions)
+
+	assert.Equal(t, srv.URL, os.Getenv(imdstest.EndpointEnv))
+
+	code, body := get(t, os.Getenv(imdstest.EndpointEnv)+"/latest/meta-data/instance-id")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "i-0decb1524582da041", body)
+}
+
+func TestServerShutdownOnCleanup(t *testing.T) {
+	var url string
+	t.Run("Start", func(t *testing.T) {
+		url = imdstest.NewServer(t, imds.DefaultOptions).URL
+	})
+
+	_, err := http.Get(url + "/latest/meta-data")
+	require.Error(t, err)
+}
+
+func TestServerInvalidOptions(t *testing.T) {
+	opts := imds.DefaultOptions
+	opts.Metadata = []byte(`{`)
+
+	ft := &fatalT{TB: t}
+	func() {
+		defer func() { recover() }()
+		imdstest.NewServer(ft, opts)
+	}()
+
+	assert.Contains(t, ft.msg, "imdstest: failed to configure IMDS mock")
+}
+
+func TestServerJSONPatch(t *testing.T) {
+	srv := imdstest.NewServer(t, imds.DefaultOptions)
+	require.Equal(t, "10.0.1.100", mustGet(t, srv.URL+"/latest/meta-data/local-ipv4"))
+
+	srv.JSONPatch(`[{"op": "replace", "path": "/local-ipv4", "value": "10.0.1.200"}]`)
+
+	assert.Equal(t, "10.0.1.200", mustGet(t, srv.URL+"/latest/meta-data/local-ipv4"))
+}
+
+func TestServerMergePatch(t *testing.T) {
+	srv := imdstest.NewServer(t, imds.DefaultOptions)
+	require.Equal(t, "imds-mock-ec2", mustGet(t, srv.URL+"/latest/meta-data/tags/instance/Name"))
+
+	srv.MergePatch(`{"tags": {"instance": {"Name": "patched"}}}`)
+
+	assert.Equal(t, "patched", mustGet(t, srv.URL+"/latest/meta-data/tags/instance/Name"))
+}
+
+func TestServerSpotInterruption(t *testing.T) {
+	opts := imds.DefaultOptions
+	opts.Spot = true
+	opts.SpotNoNotice = true
+
+	srv := imdstest.NewServer(t, opts)
+	code, _ := get(t, srv.URL+"/latest/meta-data/spot/instance-action")
+	require.Equal(t, http.StatusNotFound, code)
+
+	srv.RecommendSpotRebalance()
+	code, _ = get(t, srv.URL+"/latest/meta-data/events/recommendations/rebalance")
+	assert.Equal(t, http.StatusOK, code)
+
+	srv.InterruptSpot(patch.StopSpotInstanceAction)
+	assert.Equal(t, "stop", gjson.Get(mustGet(t, srv.URL+"/latest/meta-data/spot/instance-action"), "action").String())
+
+	srv.WithdrawSpotInterruption()
+	code, _ = get(t, srv.URL+"/latest/meta-data/spot/instance-action")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func mustGet(t *testing.T, url string) string {
+	t.Helper()
+
+	code, body := get(t, url)
+	require.Equal(t, http.StatusOK, code)
+	return body
+}
+
+// Captures a fatal test failure without failing the parent test
+type fatalT struct {
+	testing.TB
+	msg string
+}
+
+func (f *fatalT) Fatalf(format string, args ...interface{}) {
+	f.msg = fmt.Sprintf(format, args...)
+	panic(f.msg)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package journal
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultCapacity defines the default number of requests retained by a journal
+const DefaultCapacity = 1000
+
+// Entry records a single request handled by the IMDS mock
+type Entry struct {
+	// Time the request was received
+	Time time.Time `json:"time"`
+
+	// Method of the request
+	Method string `json:"method"`
+
+	// Path of the request
+	Path string `json:"path"`
+
+	// Headers sent with the request
+	Headers http.Header `json:"headers"`
+
+	// TokenProvided is true if the request contained an IMDSv2 session token
+	TokenProvided bool `json:"tokenProvided"`
+
+	// TokenValid is true if the request contained a valid IMDSv2 session token
+	TokenValid bool `json:"tokenValid"`
+
+	// Status code of the response
+	Status int `json:"status"`
+}
+
+// Journal is a bounded in-memory record of requests handled by the IMDS mock.
+// Once full, the oldest entry is discarded for every new entry recorded
+type Journal struct {
+	entries  []Entry
+	next     int
+	full     bool
+	capacity int
+	mu       sync.RWMutex
+}
+
+// New creates an empty journal that retains up to the given number of entries.
+// If the capacity is not positive, the DefaultCapacity is used
+func New(capacity int) *Journal {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+
+	return &Journal{
+		entries:  make([]Entry, capacity),
+		capacity: capacity,
+	}
+}
+
+// Record an entry within the journal
+func (j *Journal) Record(entry Entry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries[j.next] = entry
+	j.next = (j.next + 1) % j.capacity
+	if j.next == 0 {
+		j.full = true
+	}
+}
+
+// Entries returns a copy of all entries within the journal, ordered from oldest to newest
+func (j *Journal) Entries() []Entry {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if !j.full {
+		return append([]Entry{}, j.entries[:j.next]...)
+	}
+
+	return append(append([]Entry{}, j.entries[j.next:]...), j.entries[:j.next]...)
+}
+
+// Clear removes all entries from the journal
+func (j *Journal) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries = make([]Entry, j.capacity)
+	j.next = 0
+	j.full = false
+}
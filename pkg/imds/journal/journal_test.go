@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package journal_test
+
+import (
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/journal"
+	"github.com/stretchr/testify/assert"
+)
+
+func paths(entries []journal.Entry) []string {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry.Path)
+	}
+	return out
+}
+
+func TestRecord(t *testing.T) {
+	j := journal.New(3)
+	j.Record(journal.Entry{Path: "/a"})
+	j.Record(journal.Entry{Path: "/b"})
+
+	assert.Equal(t, []string{"/a", "/b"}, paths(j.Entries()))
+}
+
+func TestRecordDiscardsOldest(t *testing.T) {
+	j := journal.New(3)
+	for _, path := range []string{"/a", "/b", "/c", "/d", "/e"} {
+		j.Record(journal.Entry{Path: path})
+	}
+
+	assert.Equal(t, []string{"/c", "/d", "/e"}, paths(j.Entries()))
+}
+
+func TestClear(t *testing.T) {
+	j := journal.New(2)
+	j.Record(journal.Entry{Path: "/a"})
+	j.Record(journal.Entry{Path: "/b"})
+	j.Clear()
+
+	assert.Empty(t, j.Entries())
+
+	j.Record(journal.Entry{Path: "/c"})
+	assert.Equal(t, []string{"/c"}, paths(j.Entries()))
+}
+
+func TestNewDefaultCapacity(t *testing.T) {
+	j := journal.New(0)
+	for i := 0; i < journal.DefaultCapacity+1; i++ {
+		j.Record(journal.Entry{})
+	}
+
+	assert.Len(t, j.Entries(), journal.DefaultCapacity)
+}
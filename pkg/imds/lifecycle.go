@@ -0,0 +1,211 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Server manages the lifecycle of an IMDS mock, serving both IMDS and admin
+// requests from their own ports until shutdown
+type Server struct {
+	mock    *Mock
+	imds    *http.Server
+	admin   *http.Server
+	ln      net.Listener
+	adminLn net.Listener
+	done    chan struct{}
+	err     error
+	once    sync.Once
+	mu      sync.Mutex
+}
+
+// NewServer configures an IMDS mock based on the incoming options, ready to be started
+func NewServer(opts Options) (*Server, error) {
+	m, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{mock: m}, nil
+}
+
+// Start configures an IMDS mock based on the incoming options and starts serving
+// requests in the background, returning a server that manages its lifecycle
+func Start(ctx context.Context, opts Options) (*Server, error) {
+	srv, err := NewServer(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := srv.Start(ctx); err != nil {
+		srv.mock.Close()
+		return nil, err
+	}
+
+	return srv, nil
+}
+
+// Mock returns the IMDS mock managed by the server
+func (s *Server) Mock() *Mock {
+	return s.mock
+}
+
+// Start listens on the configured ports and serves requests in the background. A
+// port of 0 will select a random free port. The context is shared by all requests
+// to the server, cancelling it will abort any request in flight
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.imds != nil {
+		return errors.New("server has already been started")
+	}
+
+	var lc net.ListenConfig
+
+	ln, err := lc.Listen(ctx, "tcp", ":"+strconv.Itoa(s.mock.opts.Port))
+	if err != nil {
+		return err
+	}
+
+	// The admin API is opt-in and served on its own port
+	var adminLn net.Listener
+	if s.mock.opts.AdminPort > 0 {
+		if adminLn, err = lc.Listen(ctx, "tcp", ":"+strconv.Itoa(s.mock.opts.AdminPort)); err != nil {
+			ln.Close()
+			return err
+		}
+	}
+
+	baseContext := func(net.Listener) context.Context { return ctx }
+
+	s.done = make(chan struct{})
+	s.ln = ln
+	s.imds = &http.Server{Handler: s.mock.router, BaseContext: baseContext}
+	go s.serve(s.imds, ln)
+
+	if adminLn != nil {
+		s.adminLn = adminLn
+		s.admin = &http.Server{Handler: s.mock.admin, BaseContext: baseContext}
+		go s.serve(s.admin, adminLn)
+	}
+
+	return nil
+}
+
+func (s *Server) serve(srv *http.Server, ln net.Listener) {
+	err := srv.Serve(ln)
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	// Only the first server to stop determines the reason
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+// Done returns a channel that is closed once either the IMDS or admin server
+// stops serving requests, whether from a shutdown or an error. A nil channel
+// is returned if the server has not been started
+func (s *Server) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.done
+}
+
+// Err returns the reason the server stopped serving requests. A nil error is
+// returned if the server is still serving requests or was shutdown gracefully
+func (s *Server) Err() error {
+	select {
+	case <-s.Done():
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Blocks until the server stops serving requests, returning the reason why
+func (s *Server) wait() error {
+	<-s.Done()
+	return s.Err()
+}
+
+// Addr returns the address the server is listening on for IMDS requests. An
+// empty address is returned if the server has not been started
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ln == nil {
+		return ""
+	}
+	return s.ln.Addr().String()
+}
+
+// AdminAddr returns the address the server is listening on for admin requests.
+// An empty address is returned if the server has not been started or the admin
+// API has not been enabled
+func (s *Server) AdminAddr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.adminLn == nil {
+		return ""
+	}
+	return s.adminLn.Addr().String()
+}
+
+// Shutdown gracefully stops the server, waiting for any active connections to drain
+// before closing the mock. Every request handled is therefore included within any
+// report or archive written on close. If the context expires before all connections
+// have drained, the error of the context is returned
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Release any request being held open, so it can drain
+	s.mock.termination.stop()
+	defer s.mock.Close()
+
+	var err error
+	for _, srv := range []*http.Server{s.imds, s.admin} {
+		if srv == nil {
+			continue
+		}
+
+		if shutdownErr := srv.Shutdown(ctx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerDoneOnServeError(t *testing.T) {
+	opts := DefaultOptions
+	opts.AutoStart = false
+	opts.Port = 0
+
+	srv, err := NewServer(opts)
+	require.NoError(t, err)
+	require.NoError(t, srv.Start(context.Background()))
+	defer srv.Shutdown(context.Background())
+
+	// Simulate the IMDS server failing, without a graceful shutdown
+	srv.ln.Close()
+
+	select {
+	case <-srv.Done():
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after failing")
+	}
+	assert.Error(t, srv.Err())
+}
+
+func TestServerShutdownDrainsBeforeClose(t *testing.T) {
+	opts := DefaultOptions
+	opts.AutoStart = false
+	opts.Port = 0
+	opts.LogLevel = "off"
+	opts.HARFile = filepath.Join(t.TempDir(), "imds.har")
+
+	srv, err := NewServer(opts)
+	require.NoError(t, err)
+
+	// Simulate a request that is still in flight when shutdown begins
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	srv.mock.router.GET("/slow", func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.String(http.StatusOK, "done")
+	})
+	require.NoError(t, srv.Start(context.Background()))
+
+	go http.Get("http://" + srv.Addr() + "/slow") // nolint: errcheck
+	<-entered
+
+	shutdown := make(chan error)
+	go func() { shutdown <- srv.Shutdown(context.Background()) }()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	require.NoError(t, <-shutdown)
+
+	data, err := os.ReadFile(opts.HARFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "/slow")
+}
+
+func TestNewReleasesEventsOnError(t *testing.T) {
+	var scheduler *event.Scheduler
+	newScheduler = func() *event.Scheduler {
+		scheduler = event.NewScheduler()
+		return scheduler
+	}
+	defer func() { newScheduler = event.NewScheduler }()
+
+	opts := DefaultOptions
+	opts.AutoStart = false
+	opts.LogFile = filepath.Join(t.TempDir(), "missing", "imds.log")
+
+	_, err := New(opts)
+	require.ErrorContains(t, err, "failed to open log file")
+
+	// Credential rotation was scheduled before the logger failed to open
+	assert.Equal(t, 0, scheduler.Pending())
+	scheduler.Once(time.Millisecond, func() { t.Error("scheduler was not stopped") })
+	time.Sleep(5 * time.Millisecond)
+}
+
+func TestNewHandlerCloseStopsEvents(t *testing.T) {
+	var scheduler *event.Scheduler
+	newScheduler = func() *event.Scheduler {
+		scheduler = event.NewScheduler()
+		return scheduler
+	}
+	defer func() { newScheduler = event.NewScheduler }()
+
+	opts := DefaultOptions
+	opts.LogLevel = "off"
+
+	_, closeHandler, err := NewHandler(opts)
+	require.NoError(t, err)
+
+	// Credentials are rotated ahead of them expiring
+	require.Equal(t, 1, scheduler.Pending())
+
+	closeHandler()
+	assert.Equal(t, 0, scheduler.Pending())
+}
@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startServer(t *testing.T, opts imds.Options) *imds.Server {
+	t.Helper()
+
+	opts.Port = 0
+	srv, err := imds.NewServer(opts)
+	require.NoError(t, err)
+	require.NoError(t, srv.Start(context.Background()))
+
+	return srv
+}
+
+func TestServerStartAndShutdown(t *testing.T) {
+	srv := startServer(t, testOptions)
+	require.NotEmpty(t, srv.Addr())
+	assert.Empty(t, srv.AdminAddr())
+
+	resp, err := http.Get("http://" + srv.Addr() + "/latest/meta-data/instance-id")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	_, err = http.Get("http://" + srv.Addr() + "/latest/meta-data/instance-id")
+	assert.Error(t, err)
+}
+
+func TestServerDoneAfterShutdown(t *testing.T) {
+	srv := startServer(t, testOptions)
+
+	select {
+	case <-srv.Done():
+		t.Fatal("server stopped before shutdown")
+	default:
+	}
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	select {
+	case <-srv.Done():
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after shutdown")
+	}
+	assert.NoError(t, srv.Err())
+}
+
+func TestServerAddrBeforeStart(t *testing.T) {
+	srv, err := imds.NewServer(testOptions)
+	require.NoError(t, err)
+
+	assert.Empty(t, srv.Addr())
+}
+
+func TestServerStartTwice(t *testing.T) {
+	srv := startServer(t, testOptions)
+	defer srv.Shutdown(context.Background())
+
+	require.EqualError(t, srv.Start(context.Background()), "server has already been started")
+}
+
+func TestServerShutdownCancelsEvents(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotAction = imds.SpotActionEvent{
+		Action:   patch.StopSpotInstanceAction,
+		Duration: 50 * time.Millisecond,
+	}
+
+	srv := startServer(t, opts)
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	// Crude sleep to ensure the cancelled event would have fired
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, http.StatusNotFound, get(t, srv.Mock().Router(), "/latest/meta-data/spot/instance-action").Code)
+}
+
+func TestServerShutdownReleasesHangingRequests(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotAction = imds.SpotActionEvent{Action: patch.TerminateSpotInstanceAction}
+	opts.SpotNoticeLead = time.Millisecond
+	opts.SpotTermination = imds.HangTermination
+
+	srv := startServer(t, opts)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if resp, err := http.Get("http://" + srv.Addr() + "/latest/meta-data"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// Crude sleep to ensure the request is being held open
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, srv.Shutdown(ctx))
+	<-done
+}
+
+func TestStart(t *testing.T) {
+	opts := testOptions
+	opts.Port = 0
+
+	srv, err := imds.Start(context.Background(), opts)
+	require.NoError(t, err)
+	defer srv.Shutdown(context.Background())
+
+	resp, err := http.Get("http://" + srv.Addr() + "/latest/meta-data/local-ipv4")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestStartPortInUse(t *testing.T) {
+	running := startServer(t, testOptions)
+	defer running.Shutdown(context.Background())
+
+	_, port, _ := net.SplitHostPort(running.Addr())
+
+	opts := testOptions
+	opts.Port, _ = strconv.Atoi(port)
+
+	_, err := imds.Start(context.Background(), opts)
+	require.Error(t, err)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Format defines how log entries are encoded
+type Format string
+
+const (
+	// JSONFormat encodes each log entry as a single line of JSON
+	JSONFormat Format = "json"
+
+	// ConsoleFormat encodes each log entry as human-readable text
+	ConsoleFormat Format = "console"
+
+	// OffLevel disables all logging
+	OffLevel = "off"
+
+	// Default destination of all log entries
+	stderr = "stderr"
+)
+
+// Options provides a set of options for configuring a logger
+type Options struct {
+	// Level defines the minimum level of any logged entry, either debug, info,
+	// warn, error or off. By default info is used
+	Level string
+
+	// Format defines how each log entry is encoded. By default JSON is used
+	Format Format
+
+	// File defines the path of a file that all log entries are appended to. By
+	// default entries are written to stderr
+	File string
+}
+
+// ParseFormat converts a string into a supported log format
+func ParseFormat(format string) (Format, error) {
+	switch logFormat := Format(format); logFormat {
+	case JSONFormat, ConsoleFormat:
+		return logFormat, nil
+	}
+
+	return "", fmt.Errorf("%s is not a supported log format expecting (%s or %s)",
+		format, JSONFormat, ConsoleFormat)
+}
+
+// ValidateLevel ensures the log level is supported, either debug, info, warn, error
+// or off. An empty level is treated as info
+func ValidateLevel(level string) error {
+	_, _, err := parseLevel(level)
+	return err
+}
+
+func parseLevel(level string) (zapcore.Level, bool, error) {
+	switch strings.ToLower(level) {
+	case "":
+		return zapcore.InfoLevel, false, nil
+	case OffLevel:
+		return zapcore.InfoLevel, true, nil
+	case "debug", "info", "warn", "error":
+		lvl, _ := zapcore.ParseLevel(level)
+		return lvl, false, nil
+	}
+
+	return zapcore.InfoLevel, false, fmt.Errorf("%s is not a supported log level expecting (debug, info, warn, error or %s)",
+		level, OffLevel)
+}
+
+// New creates a logger from the provided options. Any header containing an IMDSv2
+// session token, or IAM secret material, is redacted from every logged field. The
+// returned function must be called to flush and close the logger once finished with
+func New(opts Options) (*zap.Logger, func(), error) {
+	level, off, err := parseLevel(opts.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var encoder zapcore.Encoder
+	switch opts.Format {
+	case "", JSONFormat:
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	case ConsoleFormat:
+		cfg := zap.NewDevelopmentEncoderConfig()
+		cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		if opts.File != "" {
+			// Avoid writing escape codes to a file
+			cfg.EncodeLevel = zapcore.CapitalLevelEncoder
+		}
+		encoder = zapcore.NewConsoleEncoder(cfg)
+	default:
+		_, err := ParseFormat(string(opts.Format))
+		return nil, nil, err
+	}
+
+	if off {
+		return zap.NewNop(), func() {}, nil
+	}
+
+	path := opts.File
+	if path == "" {
+		path = stderr
+	}
+
+	sink, closeSink, err := zap.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	// Replicates the sampling of a zap production logger. Redaction must wrap the
+	// core that writes entries, as the sampler only samples when checking an entry
+	core := zapcore.NewSamplerWithOptions(Redact(zapcore.NewCore(encoder, sink, level)), time.Second, 100, 100)
+
+	logger := zap.New(core,
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(sink))
+
+	return logger, func() {
+		logger.Sync() // nolint: errcheck
+		closeSink()
+	}, nil
+}
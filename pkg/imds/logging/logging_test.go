@@ -0,0 +1,252 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package logging_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseFormat(t *testing.T) {
+	format, err := logging.ParseFormat("console")
+
+	require.NoError(t, err)
+	assert.Equal(t, logging.ConsoleFormat, format)
+}
+
+func TestParseFormatUnsupported(t *testing.T) {
+	_, err := logging.ParseFormat("xml")
+
+	require.EqualError(t, err, "xml is not a supported log format expecting (json or console)")
+}
+
+func TestValidateLevel(t *testing.T) {
+	for _, level := range []string{"", "debug", "INFO", "warn", "error", "off"} {
+		assert.NoError(t, logging.ValidateLevel(level), level)
+	}
+
+	assert.EqualError(t, logging.ValidateLevel("verbose"), "verbose is not a supported log level expecting (debug, info, warn, error or off)")
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     logging.Options
+		contains string
+	}{
+		{
+			name:     "JSON",
+			opts:     logging.Options{Format: logging.JSONFormat},
+			contains: `"msg":"logged"`,
+		},
+		{
+			name:     "Console",
+			opts:     logging.Options{Format: logging.ConsoleFormat},
+			contains: "INFO",
+		},
+		{
+			name:     "Defaults",
+			opts:     logging.Options{},
+			contains: `"level":"info"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.File = filepath.Join(t.TempDir(), "imds-mock.log")
+
+			logger, closeLogger, err := logging.New(tt.opts)
+			require.NoError(t, err)
+
+			logger.Debug("ignored")
+			logger.Info("logged")
+			closeLogger()
+
+			out, err := os.ReadFile(tt.opts.File)
+			require.NoError(t, err)
+			assert.Contains(t, string(out), tt.contains)
+			assert.Contains(t, string(out), "logged")
+			assert.NotContains(t, string(out), "ignored")
+		})
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "imds-mock.log")
+
+	logger, closeLogger, err := logging.New(logging.Options{Level: "warn", File: file})
+	require.NoError(t, err)
+
+	logger.Info("ignored")
+	logger.Warn("logged")
+	closeLogger()
+
+	out, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "logged")
+	assert.NotContains(t, string(out), "ignored")
+}
+
+func TestNew_SampledAndRedacted(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "imds-mock.log")
+
+	logger, closeLogger, err := logging.New(logging.Options{File: file})
+	require.NoError(t, err)
+
+	// After the first 100 identical entries, only every 100th is logged within a second
+	for i := 0; i < 300; i++ {
+		logger.Info("sampled", zap.String("token", "secret"))
+	}
+	closeLogger()
+
+	out, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	assert.Len(t, lines, 102)
+	for _, line := range lines {
+		assert.Contains(t, line, `"token":"[REDACTED]"`)
+	}
+	assert.NotContains(t, string(out), "secret")
+}
+
+func TestNew_Off(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "imds-mock.log")
+
+	logger, closeLogger, err := logging.New(logging.Options{Level: logging.OffLevel, File: file})
+	require.NoError(t, err)
+
+	logger.Error("ignored")
+	closeLogger()
+
+	assert.NoFileExists(t, file)
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   logging.Options
+		errMsg string
+	}{
+		{
+			name:   "UnsupportedLevel",
+			opts:   logging.Options{Level: "trace"},
+			errMsg: "trace is not a supported log level expecting (debug, info, warn, error or off)",
+		},
+		{
+			name:   "UnsupportedFormat",
+			opts:   logging.Options{Format: "xml"},
+			errMsg: "xml is not a supported log format expecting (json or console)",
+		},
+		{
+			name:   "MissingDirectory",
+			opts:   logging.Options{File: filepath.Join("does-not-exist", "imds-mock.log")},
+			errMsg: "failed to open log file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := logging.New(tt.opts)
+
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-aws-ec2-metadata-token", "secret-token")
+	header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+
+	tests := []struct {
+		name     string
+		field    zapcore.Field
+		expected interface{}
+	}{
+		{
+			name:     "SensitiveKey",
+			field:    zap.String("X-aws-ec2-metadata-token", "secret-token"),
+			expected: logging.Redacted,
+		},
+		{
+			name:     "Header",
+			field:    zap.Any("headers", header),
+			expected: map[string]interface{}{"X-Aws-Ec2-Metadata-Token": logging.Redacted, "X-Aws-Ec2-Metadata-Token-Ttl-Seconds": []interface{}{"21600"}},
+		},
+		{
+			name: "NestedStruct",
+			field: zap.Any("credentials", struct {
+				AccessKeyID     string `json:"AccessKeyId"`
+				SecretAccessKey string
+				Token           string
+			}{AccessKeyID: "ASIA", SecretAccessKey: "secret", Token: "token"}),
+			expected: map[string]interface{}{"AccessKeyId": "ASIA", "SecretAccessKey": logging.Redacted, "Token": logging.Redacted},
+		},
+		{
+			name:     "JSONString",
+			field:    zap.String("body", `{"AccessKeyId":"ASIA","SecretAccessKey":"secret","Token":"token"}`),
+			expected: `{"AccessKeyId":"ASIA","SecretAccessKey":"[REDACTED]","Token":"[REDACTED]"}`,
+		},
+		{
+			name:     "Error",
+			field:    zap.Error(errors.New("rejected X-aws-ec2-metadata-token: secret-token")),
+			expected: "rejected X-aws-ec2-metadata-token: [REDACTED]",
+		},
+		{
+			name:     "Unchanged",
+			field:    zap.Int("status", 200),
+			expected: int64(200),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			logger := zap.New(logging.Redact(core))
+
+			logger.Info("request", tt.field)
+
+			require.Equal(t, 1, logs.Len())
+			assert.Equal(t, tt.expected, logs.All()[0].ContextMap()[tt.field.Key])
+		})
+	}
+}
+
+func TestRedact_With(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(logging.Redact(core)).With(zap.String("token", "secret-token"))
+
+	logger.Info("request")
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, logging.Redacted, logs.All()[0].ContextMap()["token"])
+}
@@ -0,0 +1,162 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the value of any sensitive field
+const Redacted = "[REDACTED]"
+
+// Keys of sensitive values, compared case insensitively
+var sensitiveKeys = map[string]struct{}{
+	"x-aws-ec2-metadata-token": {},
+	"token":                    {},
+	"sessiontoken":             {},
+	"secretaccesskey":          {},
+}
+
+// Matches sensitive values embedded within a string, either as a JSON
+// key value pair or an HTTP header
+var sensitiveValues = regexp.MustCompile(
+	`(?i)("(?:x-aws-ec2-metadata-token|token|sessiontoken|secretaccesskey)"\s*:\s*)"(?:[^"\\]|\\.)*"` +
+		`|(x-aws-ec2-metadata-token:\s*)\S+`)
+
+type redactCore struct {
+	zapcore.Core
+}
+
+// Redact wraps a core, ensuring any IMDSv2 session token or IAM secret material
+// is redacted from every field before it is logged. The redacting core adds itself
+// to every checked entry, so it must wrap the core that writes entries and be
+// wrapped by any sampler
+func Redact(core zapcore.Core) zapcore.Core {
+	return redactCore{Core: core}
+}
+
+func (c redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = redactString(ent.Message)
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		redacted = append(redacted, redactField(f))
+	}
+
+	return redacted
+}
+
+func redactField(f zapcore.Field) zapcore.Field {
+	if isSensitive(f.Key) {
+		return zap.String(f.Key, Redacted)
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = redactString(f.String)
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return zap.String(f.Key, redactString(err.Error()))
+		}
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok {
+			return zap.String(f.Key, redactString(s.String()))
+		}
+	case zapcore.ReflectType:
+		return zap.Any(f.Key, redactValue(f.Interface))
+	}
+
+	return f
+}
+
+func isSensitive(key string) bool {
+	_, ok := sensitiveKeys[strings.ToLower(key)]
+	return ok
+}
+
+func redactString(s string) string {
+	return sensitiveValues.ReplaceAllStringFunc(s, func(match string) string {
+		groups := sensitiveValues.FindStringSubmatch(match)
+		if groups[1] != "" {
+			return groups[1] + `"` + Redacted + `"`
+		}
+		return groups[2] + Redacted
+	})
+}
+
+// Converts any value into its generic JSON form, allowing keys of nested maps and
+// structs to be inspected. Any value that cannot be converted is logged as is
+func redactValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+
+	return redactGeneric(generic)
+}
+
+func redactGeneric(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, nested := range val {
+			if isSensitive(k) {
+				val[k] = Redacted
+				continue
+			}
+			val[k] = redactGeneric(nested)
+		}
+	case []interface{}:
+		for i, nested := range val {
+			val[i] = redactGeneric(nested)
+		}
+	case string:
+		return redactString(val)
+	}
+
+	return v
+}
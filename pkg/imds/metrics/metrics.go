@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace used by all metrics exposed by the IMDS mock
+const namespace = "imds_mock"
+
+// Metrics contains a set of prometheus metrics for monitoring the IMDS mock.
+// Each set uses its own registry, allowing multiple mocks to run side by side
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	tokens   prometheus.Counter
+	cache    *prometheus.CounterVec
+	events   *prometheus.CounterVec
+}
+
+// New creates and registers a set of metrics for monitoring the IMDS mock
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Total number of requests handled, by path, status and auth type.",
+		}, []string{"path", "status", "auth"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests handled, by path, status and auth type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"path", "status", "auth"}),
+		tokens: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tokens_issued_total",
+			Help:      "Total number of IMDSv2 session tokens issued.",
+		}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_requests_total",
+			Help:      "Total number of cache lookups, by result (hit or miss).",
+		}, []string{"result"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Total number of events fired, by event.",
+		}, []string{"event"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.latency,
+		m.tokens,
+		m.cache,
+		m.events,
+	)
+
+	return m
+}
+
+// ObserveRequest records a handled request along with its latency in seconds
+func (m *Metrics) ObserveRequest(path, status, auth string, seconds float64) {
+	m.requests.WithLabelValues(path, status, auth).Inc()
+	m.latency.WithLabelValues(path, status, auth).Observe(seconds)
+}
+
+// TokenIssued records the issuing of an IMDSv2 session token
+func (m *Metrics) TokenIssued() {
+	m.tokens.Inc()
+}
+
+// CacheHit records a cache lookup that returned a cached response
+func (m *Metrics) CacheHit() {
+	m.cache.WithLabelValues("hit").Inc()
+}
+
+// CacheMiss records a cache lookup that did not return a cached response
+func (m *Metrics) CacheMiss() {
+	m.cache.WithLabelValues("miss").Inc()
+}
+
+// EventFired records an event changing the state of the IMDS mock
+func (m *Metrics) EventFired(event string) {
+	m.events.WithLabelValues(event).Inc()
+}
+
+// Handler returns an HTTP handler that exposes all metrics in the prometheus
+// text based exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	m := metrics.New()
+	m.ObserveRequest("/latest/meta-data", "200", "v1", 0.01)
+	m.TokenIssued()
+	m.CacheHit()
+	m.CacheMiss()
+	m.EventFired("spot-rebalance")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+	m.Handler().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	out := w.Body.String()
+	assert.Contains(t, out, `imds_mock_requests_total{auth="v1",path="/latest/meta-data",status="200"} 1`)
+	assert.Contains(t, out, `imds_mock_request_duration_seconds_count{auth="v1",path="/latest/meta-data",status="200"} 1`)
+	assert.Contains(t, out, "imds_mock_tokens_issued_total 1")
+	assert.Contains(t, out, `imds_mock_cache_requests_total{result="hit"} 1`)
+	assert.Contains(t, out, `imds_mock_cache_requests_total{result="miss"} 1`)
+	assert.Contains(t, out, `imds_mock_events_total{event="spot-rebalance"} 1`)
+	assert.Contains(t, out, "go_goroutines")
+}
+
+func TestNew_Independent(t *testing.T) {
+	first := metrics.New()
+	first.TokenIssued()
+
+	second := metrics.New()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+	second.Handler().ServeHTTP(w, req)
+
+	assert.Contains(t, w.Body.String(), "imds_mock_tokens_issued_total 0")
+}
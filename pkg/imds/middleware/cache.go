@@ -28,25 +28,50 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/purpleclay/imds-mock/pkg/imds/cache"
+	"github.com/purpleclay/imds-mock/pkg/imds/metrics"
 )
 
 // Capture any write to the response with an in memory buffer
-type cacheWriter struct {
+type bodyWriter struct {
 	gin.ResponseWriter
 	body bytes.Buffer
 }
 
-func (w *cacheWriter) Write(data []byte) (n int, err error) {
+func (w *bodyWriter) Write(data []byte) (n int, err error) {
 	w.body.Write(data)
 	return w.ResponseWriter.Write(data)
 }
 
+// CacheOption configures the behaviour of the Cache middleware
+type CacheOption func(*cacheOptions)
+
+type cacheOptions struct {
+	metrics *metrics.Metrics
+}
+
+// WithCacheMetrics records every cache hit and miss within the provided metrics
+func WithCacheMetrics(m *metrics.Metrics) CacheOption {
+	return func(o *cacheOptions) {
+		o.metrics = m
+	}
+}
+
 // Cache provides middleware caching any request to the IMDS mock using
 // an in memory map. The IMDS response is cached using a lookup query based
-// on the IMDS instance category path
-func Cache(memcache *cache.MemCache) gin.HandlerFunc {
+// on the IMDS instance category path. Any change to the source of the cache
+// automatically invalidates every cached response
+func Cache(memcache *cache.MemCache, opts ...CacheOption) gin.HandlerFunc {
+	var cfg cacheOptions
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(c *gin.Context) {
 		if res, hit := memcache.Get(c.Request.URL.Path); hit {
+			if cfg.metrics != nil {
+				cfg.metrics.CacheHit()
+			}
+
 			c.Writer.Header().Add("Content-Type", "text/plain")
 			c.String(http.StatusOK, res)
 
@@ -54,12 +79,20 @@ func Cache(memcache *cache.MemCache) gin.HandlerFunc {
 			return
 		}
 
-		c.Writer = &cacheWriter{ResponseWriter: c.Writer}
+		if cfg.metrics != nil {
+			cfg.metrics.CacheMiss()
+		}
+
+		// Captured before the response is generated, ensuring it is never cached
+		// if the underlying source changes in the meantime
+		generation := memcache.Generation()
+
+		c.Writer = &bodyWriter{ResponseWriter: c.Writer}
 		c.Next()
 
 		// Only cache if the response is a 200, as some instance categories are event driven
 		if c.Writer.Status() == http.StatusOK {
-			memcache.Set(c.Request.URL.Path, c.Writer.(*cacheWriter).body.String())
+			memcache.Set(c.Request.URL.Path, c.Writer.(*bodyWriter).body.String(), generation)
 		}
 	}
 }
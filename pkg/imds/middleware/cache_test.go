@@ -25,19 +25,34 @@ package middleware_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/purpleclay/imds-mock/pkg/imds/cache"
+	"github.com/purpleclay/imds-mock/pkg/imds/metrics"
 	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
 	"github.com/stretchr/testify/assert"
 )
 
+// Simulates a source of cached data that can be changed
+type source struct {
+	generation uint64
+}
+
+func (s *source) Generation() uint64 {
+	return atomic.LoadUint64(&s.generation)
+}
+
+func (s *source) change() {
+	atomic.AddUint64(&s.generation, 1)
+}
+
 func TestCache_Miss(t *testing.T) {
 	count := 0
 
 	r := gin.Default()
-	r.GET("/cache", middleware.Cache(cache.New()), func(c *gin.Context) {
+	r.GET("/cache", middleware.Cache(cache.New(&source{})), func(c *gin.Context) {
 		count++
 	})
 
@@ -53,7 +68,7 @@ func TestCache_Hit(t *testing.T) {
 	count := 0
 
 	r := gin.Default()
-	r.GET("/cache", middleware.Cache(cache.New()), func(c *gin.Context) {
+	r.GET("/cache", middleware.Cache(cache.New(&source{})), func(c *gin.Context) {
 		count++
 		c.String(http.StatusOK, "ok")
 	})
@@ -71,7 +86,7 @@ func TestCache_Hit(t *testing.T) {
 }
 
 func TestCache_IgnoresNon200StatusCodes(t *testing.T) {
-	c := cache.New()
+	c := cache.New(&source{})
 
 	r := gin.Default()
 	r.GET("/cache", middleware.Cache(c), func(c *gin.Context) {
@@ -86,3 +101,61 @@ func TestCache_IgnoresNon200StatusCodes(t *testing.T) {
 	_, exists := c.Get("/cache")
 	assert.False(t, exists)
 }
+
+func TestCache_Metrics(t *testing.T) {
+	m := metrics.New()
+
+	r := gin.Default()
+	r.GET("/cache", middleware.Cache(cache.New(&source{}), middleware.WithCacheMetrics(m)), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/cache", http.NoBody)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `imds_mock_cache_requests_total{result="hit"} 2`)
+	assert.Contains(t, out, `imds_mock_cache_requests_total{result="miss"} 1`)
+}
+
+func TestCache_SourceChanged(t *testing.T) {
+	src := &source{}
+	value := "on-demand"
+
+	r := gin.Default()
+	r.GET("/cache", middleware.Cache(cache.New(src)), func(c *gin.Context) {
+		c.String(http.StatusOK, value)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/cache", http.NoBody)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	value = "spot"
+	src.change()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "spot", w.Body.String())
+}
+
+func TestCache_SourceChangedDuringRequest(t *testing.T) {
+	src := &source{}
+	count := 0
+
+	r := gin.Default()
+	r.GET("/cache", middleware.Cache(cache.New(src)), func(c *gin.Context) {
+		count++
+		c.String(http.StatusOK, "ok")
+		// Response was generated from a source that has since changed
+		src.change()
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/cache", http.NoBody)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, 2, count)
+}
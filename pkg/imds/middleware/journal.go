@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware
+
+import (
+	"net/textproto"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/journal"
+	"github.com/purpleclay/imds-mock/pkg/imds/token"
+)
+
+// Journal provides middleware that records every request handled by the IMDS
+// mock within a journal, once a response has been written. Any session token
+// is verified using the provided verifier
+func Journal(j *journal.Journal, verifier token.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		received := time.Now().UTC()
+
+		// Headers are stored in a canonical format
+		_, tokenProvided := c.Request.Header[textproto.CanonicalMIMEHeaderKey(V2TokenHeader)]
+
+		c.Next()
+
+		j.Record(journal.Entry{
+			Time:          received,
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			Headers:       c.Request.Header.Clone(),
+			TokenProvided: tokenProvided,
+			TokenValid:    tokenProvided && validV2Token(verifier, c.Request.Header.Get(V2TokenHeader)),
+			Status:        c.Writer.Status(),
+		})
+	}
+}
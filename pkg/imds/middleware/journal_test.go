@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/journal"
+	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
+	"github.com/purpleclay/imds-mock/pkg/imds/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func journalRouter(j *journal.Journal) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.Journal(j, signer))
+	r.GET("/journal", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	return r
+}
+
+func TestJournal(t *testing.T) {
+	j := journal.New(10)
+	r := journalRouter(j)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/journal", http.NoBody)
+	req.Header.Set("User-Agent", "testing")
+	r.ServeHTTP(w, req)
+
+	entries := j.Entries()
+	require.Len(t, entries, 1)
+
+	assert.WithinDuration(t, time.Now(), entries[0].Time, time.Second)
+	assert.Equal(t, http.MethodGet, entries[0].Method)
+	assert.Equal(t, "/journal", entries[0].Path)
+	assert.Equal(t, "testing", entries[0].Headers.Get("User-Agent"))
+	assert.False(t, entries[0].TokenProvided)
+	assert.False(t, entries[0].TokenValid)
+	assert.Equal(t, http.StatusOK, entries[0].Status)
+}
+
+func TestJournal_Token(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{
+			name:  "Valid",
+			token: signer.Sign(token.NewV2(10)),
+			valid: true,
+		},
+		{
+			name:  "Invalid",
+			token: "not a token",
+			valid: false,
+		},
+		{
+			name:  "Expired",
+			token: signer.Sign(token.NewV2(-1)),
+			valid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := journal.New(10)
+			r := journalRouter(j)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/journal", http.NoBody)
+			req.Header.Set(middleware.V2TokenHeader, tt.token)
+			r.ServeHTTP(w, req)
+
+			entries := j.Entries()
+			require.Len(t, entries, 1)
+			assert.True(t, entries[0].TokenProvided)
+			assert.Equal(t, tt.valid, entries[0].TokenValid)
+		})
+	}
+}
+
+func TestJournal_RecordsStatus(t *testing.T) {
+	j := journal.New(10)
+	r := journalRouter(j)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/unknown", http.NoBody)
+	r.ServeHTTP(w, req)
+
+	entries := j.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, http.StatusNotFound, entries[0].Status)
+}
@@ -32,17 +32,35 @@ type JSONFormatter interface {
 	Format(in []byte) []byte
 }
 
+// Context key used to flag a response that must be written without formatting
+const rawResponseKey = "imds-mock/raw-response"
+
 // A crude wrapper around a gin.ResponseWriter for supporting the custom
 // injection of a JSON formatter
 type jsonRewriter struct {
 	gin.ResponseWriter
 	Formatter JSONFormatter
+	ctx       *gin.Context
 }
 
 func (w *jsonRewriter) Write(data []byte) (n int, err error) {
+	if w.ctx.GetBool(rawResponseKey) {
+		return w.ResponseWriter.Write(data)
+	}
+
 	return w.ResponseWriter.Write(w.Formatter.Format(data))
 }
 
+// RawResponse provides middleware that ensures a response is written exactly
+// as provided, bypassing any JSON formatting. Required when serving payloads
+// such as user data or signed documents
+func RawResponse() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(rawResponseKey, true)
+		c.Next()
+	}
+}
+
 type compactJSONFormatter struct{}
 
 func (f compactJSONFormatter) Format(in []byte) []byte {
@@ -64,6 +82,7 @@ func CompactJSON() gin.HandlerFunc {
 		c.Writer = &jsonRewriter{
 			ResponseWriter: c.Writer,
 			Formatter:      compactJSONFormatter{},
+			ctx:            c,
 		}
 
 		c.Next()
@@ -98,6 +117,7 @@ func PrettyJSON() gin.HandlerFunc {
 		c.Writer = &jsonRewriter{
 			ResponseWriter: c.Writer,
 			Formatter:      prettyJSONFormatter{},
+			ctx:            c,
 		}
 
 		c.Next()
@@ -123,3 +123,17 @@ func TestPrettyJSON_IgnoreEmptyString(t *testing.T) {
 
 	assert.Empty(t, w.Body.String())
 }
+
+func TestRawResponse(t *testing.T) {
+	r := gin.Default()
+	r.GET("/", middleware.CompactJSON(), middleware.RawResponse(), func(c *gin.Context) {
+		c.String(http.StatusOK, `{ "a": "1" }`)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, `{ "a": "1" }`, w.Body.String())
+}
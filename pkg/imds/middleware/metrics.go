@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
+	"github.com/purpleclay/imds-mock/pkg/imds/metrics"
+)
+
+// Path label used for any request that does not match a route or category
+const unmatchedPath = "unmatched"
+
+// Metrics provides middleware that records the count and latency of every request
+// handled by the IMDS mock, by path, status and auth type. A request is deemed to
+// be V2 if it provides a session token. Paths are normalised into categories, keeping
+// the number of label values bounded, see audit.NormaliseCategory
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		auth := "v1"
+		// Headers are stored in a canonical format
+		if _, exists := c.Request.Header[textproto.CanonicalMIMEHeaderKey(V2TokenHeader)]; exists {
+			auth = "v2"
+		}
+
+		c.Next()
+
+		m.ObserveRequest(metricsPath(c), strconv.Itoa(c.Writer.Status()), auth, time.Since(start).Seconds())
+	}
+}
+
+// Prevent unbounded label values from any client controlled path
+func metricsPath(c *gin.Context) string {
+	route := c.FullPath()
+	if route == "" || c.Writer.Status() == http.StatusNotFound {
+		return unmatchedPath
+	}
+
+	// A rejected request to a wildcard route may not resolve to a category
+	if c.Writer.Status() >= http.StatusBadRequest && strings.ContainsAny(route, "*:") {
+		return audit.NormaliseCategory(route)
+	}
+
+	return audit.NormaliseCategory(c.Request.URL.Path)
+}
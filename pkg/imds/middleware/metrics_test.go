@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/metrics"
+	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+	m.Handler().ServeHTTP(w, req)
+
+	return w.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		token   string
+		metrics string
+	}{
+		{
+			name:    "V1",
+			path:    "/metrics",
+			metrics: `imds_mock_requests_total{auth="v1",path="metrics",status="200"} 1`,
+		},
+		{
+			name:    "V2",
+			path:    "/metrics",
+			token:   "token",
+			metrics: `imds_mock_requests_total{auth="v2",path="metrics",status="200"} 1`,
+		},
+		{
+			name:    "Unmatched",
+			path:    "/unknown",
+			metrics: `imds_mock_requests_total{auth="v1",path="unmatched",status="404"} 1`,
+		},
+		{
+			name:    "Category",
+			path:    "/latest/meta-data/instance-id",
+			metrics: `imds_mock_requests_total{auth="v1",path="meta-data/instance-id",status="200"} 1`,
+		},
+		{
+			name:    "NormalisedCategory",
+			path:    "/latest/meta-data/network/interfaces/macs/06:e5:43:29:8c:08/mac",
+			metrics: `imds_mock_requests_total{auth="v1",path="meta-data/network/interfaces/macs/{mac}/mac",status="200"} 1`,
+		},
+		{
+			name:    "UnknownCategory",
+			path:    "/latest/meta-data/unknown-12345",
+			metrics: `imds_mock_requests_total{auth="v1",path="unmatched",status="404"} 1`,
+		},
+		{
+			name:    "RejectedCategory",
+			path:    "/latest/meta-data/denied-12345",
+			metrics: `imds_mock_requests_total{auth="v1",path="meta-data/*category",status="401"} 1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.New()
+
+			r := gin.New()
+			r.Use(middleware.Metrics(m))
+			r.GET("/metrics", func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+			r.GET("/latest/meta-data/*category", func(c *gin.Context) {
+				switch {
+				case strings.HasPrefix(c.Param("category"), "/unknown"):
+					c.Status(http.StatusNotFound)
+				case strings.HasPrefix(c.Param("category"), "/denied"):
+					c.Status(http.StatusUnauthorized)
+				default:
+					c.String(http.StatusOK, "ok")
+				}
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			if tt.token != "" {
+				req.Header.Set(middleware.V2TokenHeader, tt.token)
+			}
+			r.ServeHTTP(w, req)
+
+			out := scrape(t, m)
+			assert.Contains(t, out, tt.metrics)
+			assert.Contains(t, out, "imds_mock_request_duration_seconds_count")
+		})
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/textproto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
+)
+
+// Usage provides middleware that records the category of every request handled
+// by the IMDS mock, along with whether a session token was provided. Only categories
+// that were served are recorded, any request that does not match a route or is
+// rejected is ignored
+func Usage(u *audit.Usage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Headers are stored in a canonical format
+		_, v2 := c.Request.Header[textproto.CanonicalMIMEHeaderKey(V2TokenHeader)]
+
+		c.Next()
+
+		// Prevent unbounded growth from probing categories that do not exist
+		if c.FullPath() == "" || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		u.Record(c.Request.URL.Path, v2)
+	}
+}
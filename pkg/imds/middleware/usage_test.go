@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
+	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage(t *testing.T) {
+	u := audit.NewUsage()
+
+	r := gin.New()
+	r.Use(middleware.Usage(u))
+	r.GET("/latest/meta-data/*category", func(c *gin.Context) {
+		if c.Param("category") == "/does-not-exist" {
+			c.String(http.StatusNotFound, "not found")
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	req.Header.Set(middleware.V2TokenHeader, "token")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest(http.MethodGet, "/unknown", http.NoBody)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest(http.MethodGet, "/latest/meta-data/does-not-exist", http.NoBody)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []audit.CategoryUsage{
+		{Category: "meta-data/instance-id", Count: 2, V1: 1, V2: 1},
+	}, u.Report().Categories)
+}
@@ -26,22 +26,57 @@ import (
 	"net/textproto"
 
 	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
+	"github.com/purpleclay/imds-mock/pkg/imds/token"
+	"go.uber.org/zap"
 )
 
+// V1Option configures the behaviour of the V1OptionalV2 middleware
+type V1Option func(*v1Options)
+
+type v1Options struct {
+	audit  *audit.V1
+	logger *zap.Logger
+}
+
+// WithV1Audit enables an audit mode, where any V1 request is served as normal, but
+// is also logged and counted separately within the provided audit
+func WithV1Audit(a *audit.V1, logger *zap.Logger) V1Option {
+	return func(o *v1Options) {
+		o.audit = a
+		o.logger = logger
+	}
+}
+
 // V1OptionalV2 provides middleware that enables both IMDSv1 and IMDSv2 support.
 // While using V1 all requests will pass straight through without any authorisation
 // checks. V2 checking will only be carried out on the presence of the HTTP header:
 //
 //	X-aws-ec2-metadata-token: TOKEN
-func V1OptionalV2() gin.HandlerFunc {
+//
+// Any V2 session token must be accepted by the verifier
+func V1OptionalV2(verifier token.Verifier, opts ...V1Option) gin.HandlerFunc {
+	var cfg v1Options
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(c *gin.Context) {
 		// Headers are stored in a canonical format
 		if _, exists := c.Request.Header[textproto.CanonicalMIMEHeaderKey(V2TokenHeader)]; exists {
 			// Treat this exactly like a V2 request
-			if !validV2Token(c.Request.Header.Get(V2TokenHeader)) {
+			if !validV2Token(verifier, c.Request.Header.Get(V2TokenHeader)) {
 				abortUnauthorised(c)
 				return
 			}
+		} else if cfg.audit != nil {
+			cfg.audit.Record(c.Request.URL.Path, c.Request.UserAgent())
+
+			if cfg.logger != nil {
+				cfg.logger.Warn("IMDSv1 request",
+					zap.String("path", c.Request.URL.Path),
+					zap.String("user-agent", c.Request.UserAgent()))
+			}
 		}
 
 		c.Next()
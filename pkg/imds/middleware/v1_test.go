@@ -28,8 +28,12 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
 	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
+	"github.com/purpleclay/imds-mock/pkg/imds/token"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestV1OptionalV2(t *testing.T) {
@@ -56,10 +60,36 @@ func TestV1OptionalV2_Unauthorised(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestV1OptionalV2_Audit(t *testing.T) {
+	a := audit.NewV1()
+
+	r := gin.New()
+	r.GET("/", middleware.V1OptionalV2(signer, middleware.WithV1Audit(a, zap.NewNop())), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("User-Agent", "curl/7.81.0")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// A V2 request should not be audited
+	req.Header.Set(middleware.V2TokenHeader, signer.Sign(token.NewV2(10)))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	report := a.Report()
+	assert.Equal(t, 1, report.MetadataNoToken)
+	require.Len(t, report.Requests, 1)
+	assert.Equal(t, "/", report.Requests[0].Path)
+	assert.Equal(t, "curl/7.81.0", report.Requests[0].UserAgent)
+}
+
 func v1Router(t *testing.T) *gin.Engine {
 	t.Helper()
 	r := gin.Default()
-	r.GET("/", middleware.V1OptionalV2(), func(c *gin.Context) {
+	r.GET("/", middleware.V1OptionalV2(signer), func(c *gin.Context) {
 		c.String(http.StatusOK, "ok")
 	})
 
@@ -23,8 +23,6 @@ SOFTWARE.
 package middleware
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -52,13 +50,13 @@ const (
 // StrictV2 provides middleware that explicitly enables IMDSv2 authorisation
 // through the use of session tokens. Any requests without a valid session
 // token are immediately rejected. A session token is presented to this middleware
-// through the use of an HTTP header:
+// through the use of an HTTP header, and must be accepted by the verifier:
 //
 //	X-aws-ec2-metadata-token: TOKEN
-func StrictV2() gin.HandlerFunc {
+func StrictV2(verifier token.Verifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tkn := c.Request.Header.Get(V2TokenHeader)
-		if validV2Token(tkn) {
+		if validV2Token(verifier, tkn) {
 			// Safe to proceed
 			c.Next()
 		} else {
@@ -67,22 +65,13 @@ func StrictV2() gin.HandlerFunc {
 	}
 }
 
-func validV2Token(tkn string) bool {
+func validV2Token(verifier token.Verifier, tkn string) bool {
 	if tkn == "" {
 		return false
 	}
 
-	decoded, err := base64.StdEncoding.DecodeString(tkn)
-	if err != nil {
-		return false
-	}
-
-	var st token.V2
-	if err := json.Unmarshal(decoded, &st); err != nil {
-		return false
-	}
-
-	return !st.Expired()
+	_, err := verifier.Verify(tkn)
+	return err == nil
 }
 
 func abortUnauthorised(c *gin.Context) {
@@ -23,34 +23,30 @@ SOFTWARE.
 package middleware_test
 
 import (
-	"bytes"
 	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"text/template"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
+	"github.com/purpleclay/imds-mock/pkg/imds/token"
 	"github.com/stretchr/testify/assert"
 )
 
 const (
-	// Token was generated (base64) from the following JSON: {"expire":"2022-08-10T06:49:05.415516+01:00"}
-	expiredToken = "eyJleHBpcmUiOiIyMDIyLTA4LTEwVDA2OjQ5OjA1LjQxNTUxNiswMTowMCJ9"
+	// Token was generated (base64) from the following JSON: {"expire":"2099-08-10T06:49:05.415516+01:00"}
+	forgedToken = "eyJleHBpcmUiOiIyMDk5LTA4LTEwVDA2OjQ5OjA1LjQxNTUxNiswMTowMCJ9"
 
 	// Token was generated (base64) from the following text: hello, world
 	invalidSessionToken = "aGVsbG8sIHdvcmxk"
 
 	// Token is not base64 encoded
 	notBase64Token = "hello, world"
-
-	// Template for generating a valid token
-	tokenTemplate = `{"expire":"{{ . }}"}`
 )
 
-var instanceTagPatch = template.Must(template.New("TokenTemplate").Parse(tokenTemplate))
+var signer = token.NewSigner([]byte("testing"))
 
 func TestStrictV2(t *testing.T) {
 	tests := []struct {
@@ -63,7 +59,19 @@ func TestStrictV2(t *testing.T) {
 		},
 		{
 			name:  "ExpiredToken",
-			token: expiredToken,
+			token: signer.Sign(token.V2{Expire: time.Now().Add(-time.Second)}),
+		},
+		{
+			name:  "ForgedToken",
+			token: forgedToken,
+		},
+		{
+			name:  "TamperedToken",
+			token: tamper(signer.Sign(token.NewV2(10))),
+		},
+		{
+			name:  "DifferentSecret",
+			token: token.NewSigner([]byte("another")).Sign(token.NewV2(10)),
 		},
 		{
 			name:  "UnsupportedToken",
@@ -104,14 +112,11 @@ func TestStrictV2(t *testing.T) {
 }
 
 func TestStrictV2_ValidToken(t *testing.T) {
-	var buf bytes.Buffer
-	instanceTagPatch.Execute(&buf, time.Now().Add(2*time.Second).Format(time.RFC3339))
-
 	r := v2Router(t)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
-	req.Header.Add(middleware.V2TokenHeader, base64.StdEncoding.EncodeToString(buf.Bytes()))
+	req.Header.Add(middleware.V2TokenHeader, signer.Sign(token.NewV2(2)))
 
 	r.ServeHTTP(w, req)
 
@@ -122,9 +127,16 @@ func TestStrictV2_ValidToken(t *testing.T) {
 func v2Router(t *testing.T) *gin.Engine {
 	t.Helper()
 	r := gin.Default()
-	r.GET("/", middleware.StrictV2(), func(c *gin.Context) {
+	r.GET("/", middleware.StrictV2(signer), func(c *gin.Context) {
 		c.String(http.StatusOK, "ok")
 	})
 
 	return r
 }
+
+// Flip a bit within the encoded expiry of a signed token
+func tamper(tkn string) string {
+	raw, _ := base64.StdEncoding.DecodeString(tkn)
+	raw[8] ^= 1
+	return base64.StdEncoding.EncodeToString(raw)
+}
@@ -23,6 +23,7 @@ SOFTWARE.
 package middleware
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"net/http"
@@ -32,18 +33,50 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/har"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// ZapOption configures the behaviour of the ZapLogger middleware
+type ZapOption func(*zapOptions)
+
+type zapOptions struct {
+	archive *har.Archive
+}
+
+// WithHAR records every request and response pair within the provided archive,
+// using the same timings as the logged request
+func WithHAR(archive *har.Archive) ZapOption {
+	return func(o *zapOptions) {
+		o.archive = archive
+	}
+}
+
 // ZapLogger provides middleware for logging all requests using Zap based structured logs
-func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
+func ZapLogger(logger *zap.Logger, opts ...ZapOption) gin.HandlerFunc {
+	var cfg zapOptions
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(c *gin.Context) {
+		var body *bytes.Buffer
+		if cfg.archive != nil {
+			w := &bodyWriter{ResponseWriter: c.Writer}
+			c.Writer = w
+			body = &w.body
+		}
+
 		start := time.Now()
 		c.Next()
 		end := time.Now()
 		latency := end.Sub(start)
 
+		if cfg.archive != nil {
+			cfg.archive.Record(harEntry(c, start, latency, body.Bytes()))
+		}
+
 		if len(c.Errors) > 0 {
 			for _, e := range c.Errors.Errors() {
 				logger.Error(fmt.Sprintf("%s %s", c.Request.Method, c.Request.URL.Path),
@@ -76,6 +109,45 @@ func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
 	}
 }
 
+func harEntry(c *gin.Context, start time.Time, latency time.Duration, body []byte) har.Entry {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+
+	elapsed := float64(latency) / float64(time.Millisecond)
+	bodySize := c.Request.ContentLength
+	if bodySize < 0 {
+		bodySize = 0
+	}
+
+	return har.Entry{
+		StartedDateTime: start,
+		Time:            elapsed,
+		Request: har.Request{
+			Method:      c.Request.Method,
+			URL:         fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, c.Request.URL.RequestURI()),
+			HTTPVersion: c.Request.Proto,
+			Cookies:     []har.Cookie{},
+			Headers:     har.NameValues(c.Request.Header),
+			QueryString: har.NameValues(c.Request.URL.Query()),
+			HeadersSize: -1,
+			BodySize:    bodySize,
+		},
+		Response: har.Response{
+			Status:      c.Writer.Status(),
+			StatusText:  http.StatusText(c.Writer.Status()),
+			HTTPVersion: c.Request.Proto,
+			Cookies:     []har.Cookie{},
+			Headers:     har.NameValues(c.Writer.Header()),
+			Content:     har.NewContent(body, c.Writer.Header().Get("Content-Type")),
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Timings: har.Timings{Wait: elapsed},
+	}
+}
+
 // ZapRecovery provides middleware that recovers from any panics raised within requests
 // and logs them using Zap based structured logs
 func ZapRecovery(logger *zap.Logger) gin.HandlerFunc {
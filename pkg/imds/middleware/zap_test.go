@@ -32,6 +32,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/har"
 	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -117,6 +118,42 @@ func TestZapLoggerError(t *testing.T) {
 	assert.True(t, dur > 0)
 }
 
+func TestZapLoggerHAR(t *testing.T) {
+	archive := har.New(har.DefaultCapacity)
+
+	r := gin.New()
+	r.Use(middleware.ZapLogger(zap.NewNop(), middleware.WithHAR(archive)))
+	r.GET("/testing", func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain")
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:1338/testing?a=b", http.NoBody)
+	req.Header.Set(middleware.V2TokenHeader, "token")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, "ok", w.Body.String())
+
+	entries := archive.Entries()
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.WithinDuration(t, time.Now(), entry.StartedDateTime, time.Second)
+	assert.True(t, entry.Time > 0)
+	assert.Equal(t, entry.Time, entry.Timings.Wait)
+
+	assert.Equal(t, http.MethodGet, entry.Request.Method)
+	assert.Equal(t, "http://localhost:1338/testing?a=b", entry.Request.URL)
+	assert.Equal(t, []har.NameValue{{Name: "X-Aws-Ec2-Metadata-Token", Value: "token"}}, entry.Request.Headers)
+	assert.Equal(t, []har.NameValue{{Name: "a", Value: "b"}}, entry.Request.QueryString)
+
+	assert.Equal(t, http.StatusOK, entry.Response.Status)
+	assert.Equal(t, "OK", entry.Response.StatusText)
+	assert.Equal(t, []har.NameValue{{Name: "Content-Type", Value: "text/plain"}}, entry.Response.Headers)
+	assert.Equal(t, har.Content{Size: 2, MimeType: "text/plain", Text: "ok"}, entry.Response.Content)
+}
+
 func TestZapRecovery(t *testing.T) {
 	r, observedLogs := zapObservedRouter(t)
 
@@ -23,12 +23,12 @@ SOFTWARE.
 package patch
 
 import (
-	"fmt"
+	"encoding/json"
 	"strings"
 )
 
 // JSONPairs transforms a map of key value pairs into a comma separated list
-// of JSON field pairs.
+// of JSON field pairs. Both keys and values are escaped.
 //
 //	pairs := map[string]string{
 //		"FirstName": "joe",
@@ -38,10 +38,15 @@ import (
 //	fmt.Println(out)
 //
 //	"\"FirstName\": \"joe\", \"LastName\": \"bloggs\""
+//
+// Deprecated: marshal the map directly using json.Marshal, which produces a
+// complete JSON object. This function will be removed in a future release
 func JSONPairs(in map[string]string) string {
 	pairs := make([]string, 0, len(in))
 	for k, v := range in {
-		pairs = append(pairs, fmt.Sprintf(`"%s": "%s"`, k, v))
+		key, _ := json.Marshal(k)
+		value, _ := json.Marshal(v)
+		pairs = append(pairs, string(key)+": "+string(value))
 	}
 
 	return strings.Join(pairs, ", ")
@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package patch
+
+import (
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/purpleclay/imds-mock/pkg/imds/iam"
+)
+
+// IAM is used to patch a JSON document and replicate the IAM categories exposed
+// by an instance with an attached instance profile. Temporary security credentials
+// will be exposed using the name of the IAM role, see:
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/iam-roles-for-amazon-ec2.html#instance-metadata-security-credentials
+type IAM struct {
+	Role        string
+	Info        iam.Info
+	Credentials iam.Credentials
+}
+
+// Patch the JSON document with the IAM categories, replacing any that exist. If
+// no role is provided, all IAM categories are removed, replicating an instance
+// without an instance profile. The resulting JSON document will conform to the
+// IMDS specification
+func (p IAM) Patch(in []byte) ([]byte, error) {
+	if p.Role == "" {
+		return removeIAM(in)
+	}
+
+	ops := []map[string]interface{}{
+		{
+			"op":   "add",
+			"path": "/iam",
+			"value": map[string]interface{}{
+				"info": p.Info,
+				"security-credentials": map[string]interface{}{
+					p.Role: p.Credentials,
+				},
+			},
+		},
+	}
+
+	raw, err := json.Marshal(ops)
+	if err != nil {
+		return in, err
+	}
+
+	patch, err := jsonpatch.DecodePatch(raw)
+	if err != nil {
+		return in, err
+	}
+
+	out, err := patch.Apply(in)
+	if err != nil {
+		return in, err
+	}
+
+	return out, nil
+}
+
+func removeIAM(in []byte) ([]byte, error) {
+	patch, _ := jsonpatch.DecodePatch([]byte(`[{"op": "remove", "path": "/iam"}]`))
+
+	opts := jsonpatch.NewApplyOptions()
+	opts.AllowMissingPathOnRemove = true
+
+	out, err := patch.ApplyWithOptions(in, opts)
+	if err != nil {
+		return in, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package patch_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/iam"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIAMPatch(t *testing.T) {
+	info := iam.NewInfo("arn:aws:iam::112233445566:instance-profile/testing")
+	creds := iam.NewCredentials(1 * time.Hour)
+
+	iamPatch := patch.IAM{
+		Role:        "testing",
+		Info:        info,
+		Credentials: creds,
+	}
+
+	out, err := iamPatch.Patch([]byte(`{"iam":{"security-credentials":{"old-role":{}}}}`))
+	require.NoError(t, err)
+
+	var iamJSON struct {
+		IAM struct {
+			Info                iam.Info                   `json:"info"`
+			SecurityCredentials map[string]iam.Credentials `json:"security-credentials"`
+		} `json:"iam"`
+	}
+	require.NoError(t, json.Unmarshal(out, &iamJSON))
+
+	assert.Equal(t, info, iamJSON.IAM.Info)
+	require.Len(t, iamJSON.IAM.SecurityCredentials, 1)
+	assert.Equal(t, creds, iamJSON.IAM.SecurityCredentials["testing"])
+}
+
+func TestIAMPatch_InvalidInputJSON(t *testing.T) {
+	iamPatch := patch.IAM{
+		Role: "testing",
+	}
+
+	_, err := iamPatch.Patch([]byte(`{`))
+	require.Error(t, err)
+}
+
+func TestIAMPatch_NoRole(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "RemovesIAM",
+			input: `{"iam":{"info":{}},"testing":"123"}`,
+		},
+		{
+			name:  "NoIAM",
+			input: `{"testing":"123"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := patch.IAM{}.Patch([]byte(tt.input))
+			require.NoError(t, err)
+
+			assert.JSONEq(t, `{"testing":"123"}`, string(out))
+		})
+	}
+}
@@ -23,25 +23,30 @@ SOFTWARE.
 package patch
 
 import (
-	"bytes"
-	"text/template"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
 	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/tidwall/gjson"
 )
 
-const instanceTagTemplate = `[
-	{
-		"op": "add", 
-		"path": "/tags", 
-		"value": {
-			"instance": { {{ jsonPairs .Tags }} }
-		}
-	}
-]`
+const (
+	// MaxInstanceTags is the maximum number of tags that can be assigned
+	// to an EC2 instance
+	MaxInstanceTags = 50
+
+	// MaxTagKeyLength is the maximum length of a tag key in unicode characters
+	MaxTagKeyLength = 128
+
+	// MaxTagValueLength is the maximum length of a tag value in unicode characters
+	MaxTagValueLength = 256
 
-var instanceTagPatch = template.Must(template.New("InstanceTagPatch").
-	Funcs(template.FuncMap{"jsonPairs": JSONPairs}).
-	Parse(instanceTagTemplate))
+	reservedTagPrefix = "aws:"
+)
 
 // InstanceTag is used to patch a JSON document and replicate the inclusion
 // of instance tags within the IMDS service. The same behaviour can be achieved
@@ -53,16 +58,53 @@ type InstanceTag struct {
 
 // Patch the JSON document with any provided instance tags. The resulting JSON
 // document will conform to the IMDS specification and return EC2 tags within the
-// IMDS metadata
+// IMDS metadata. Tags are validated against the EC2 tag restrictions before patching.
+// Any instance tags already defined within the document are kept, unless replaced
+// by a provided tag with the same key
 func (p InstanceTag) Patch(in []byte) ([]byte, error) {
 	if len(p.Tags) == 0 {
 		return in, nil
 	}
 
-	var buf bytes.Buffer
-	instanceTagPatch.Execute(&buf, p)
+	if err := ValidateInstanceTags(p.Tags); err != nil {
+		return in, err
+	}
+
+	tags := map[string]interface{}{}
+	existing := gjson.GetBytes(in, "tags.instance")
+	if existing.IsObject() {
+		existing.ForEach(func(key, value gjson.Result) bool {
+			tags[key.String()] = json.RawMessage(value.Raw)
+			return true
+		})
+	}
+
+	for k, v := range p.Tags {
+		tags[k] = v
+	}
+
+	op := map[string]interface{}{
+		"op":    "add",
+		"path":  "/tags/instance",
+		"value": tags,
+	}
 
-	patch, _ := jsonpatch.DecodePatch(buf.Bytes())
+	if !gjson.GetBytes(in, "tags").IsObject() {
+		op["path"] = "/tags"
+		op["value"] = map[string]interface{}{
+			"instance": tags,
+		}
+	}
+
+	raw, err := json.Marshal([]map[string]interface{}{op})
+	if err != nil {
+		return in, err
+	}
+
+	patch, err := jsonpatch.DecodePatch(raw)
+	if err != nil {
+		return in, err
+	}
 
 	out, err := patch.Apply(in)
 	if err != nil {
@@ -71,3 +113,90 @@ func (p InstanceTag) Patch(in []byte) ([]byte, error) {
 
 	return out, nil
 }
+
+// ExcludeInstanceTags is used to patch a JSON document and remove all instance
+// tags, replicating an instance without access to its tags through IMDS
+type ExcludeInstanceTags struct{}
+
+// Patch the JSON document by removing the tags category, if it exists
+func (p ExcludeInstanceTags) Patch(in []byte) ([]byte, error) {
+	patch, _ := jsonpatch.DecodePatch([]byte(`[{"op": "remove", "path": "/tags"}]`))
+
+	opts := jsonpatch.NewApplyOptions()
+	opts.AllowMissingPathOnRemove = true
+
+	out, err := patch.ApplyWithOptions(in, opts)
+	if err != nil {
+		return in, err
+	}
+
+	return out, nil
+}
+
+// ValidateInstanceTags ensures a set of instance tags adheres to the EC2 tag
+// restrictions and can be exposed through IMDS, see:
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/Using_Tags.html#tag-restrictions
+//
+// Tags are checked in key order, with the first violation being returned
+func ValidateInstanceTags(tags map[string]string) error {
+	if len(tags) > MaxInstanceTags {
+		return fmt.Errorf("too many instance tags: %d provided, a maximum of %d is supported", len(tags), MaxInstanceTags)
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := validateTagKey(k); err != nil {
+			return fmt.Errorf("invalid instance tag key %q: %w", k, err)
+		}
+
+		if n := utf8.RuneCountInString(tags[k]); n > MaxTagValueLength {
+			return fmt.Errorf("invalid instance tag value for key %q: %d characters exceeds the maximum of %d", k, n, MaxTagValueLength)
+		}
+	}
+
+	return nil
+}
+
+func validateTagKey(key string) error {
+	n := utf8.RuneCountInString(key)
+	if n == 0 {
+		return errors.New("key cannot be empty")
+	}
+
+	if n > MaxTagKeyLength {
+		return fmt.Errorf("%d characters exceeds the maximum of %d", n, MaxTagKeyLength)
+	}
+
+	if strings.HasPrefix(strings.ToLower(key), reservedTagPrefix) {
+		return fmt.Errorf("the %s prefix is reserved for use by AWS", reservedTagPrefix)
+	}
+
+	switch key {
+	case ".", "..", "_index":
+		return errors.New("key is reserved and cannot be exposed through IMDS")
+	}
+
+	for _, r := range key {
+		if !isTagKeyRune(r) {
+			return fmt.Errorf("character %q is not supported by IMDS, expecting letters, numbers or + - = . , _ : @", r)
+		}
+	}
+
+	return nil
+}
+
+// IMDS only exposes tags whose keys are restricted to letters, numbers and a
+// small set of punctuation. Most notably spaces and / are not permitted
+func isTagKeyRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+
+	return strings.ContainsRune("+-=.,_:@", r)
+}
@@ -23,6 +23,9 @@ SOFTWARE.
 package patch_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/purpleclay/imds-mock/pkg/imds/patch"
@@ -57,6 +60,56 @@ func TestInstanceTagPatch(t *testing.T) {
 `, string(pretty.PrettyOptions(out, opts)))
 }
 
+func TestInstanceTagPatch_MergesExistingTags(t *testing.T) {
+	tagPatch := patch.InstanceTag{
+		Tags: map[string]string{
+			"Name":        "testing",
+			"Environment": "dev",
+		},
+	}
+
+	out, err := tagPatch.Patch([]byte(`{"tags":{"instance":{"Team":"platform","Name":"custom"}}}`))
+	require.NoError(t, err)
+
+	opts := pretty.DefaultOptions
+	opts.SortKeys = true
+
+	assert.Equal(t, `{
+  "tags": {
+    "instance": {
+      "Environment": "dev",
+      "Name": "testing",
+      "Team": "platform"
+    }
+  }
+}
+`, string(pretty.PrettyOptions(out, opts)))
+}
+
+func TestExcludeInstanceTagsPatch(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{
+			name: "WithTags",
+			in:   `{"testing":"123","tags":{"instance":{"Name":"testing"}}}`,
+		},
+		{
+			name: "WithoutTags",
+			in:   `{"testing":"123"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := patch.ExcludeInstanceTags{}.Patch([]byte(tt.in))
+			require.NoError(t, err)
+
+			assert.JSONEq(t, `{"testing":"123"}`, string(out))
+		})
+	}
+}
+
 func TestInstanceTagPatch_NoTags(t *testing.T) {
 	tagPatch := patch.InstanceTag{
 		Tags: map[string]string{},
@@ -79,3 +132,122 @@ func TestInstanceTagPatch_InvalidInputJSON(t *testing.T) {
 	_, err := tagPatch.Patch([]byte(`{`))
 	require.Error(t, err)
 }
+
+func TestInstanceTagPatch_EscapesValues(t *testing.T) {
+	tagPatch := patch.InstanceTag{
+		Tags: map[string]string{
+			"Quoted":    `say "hello"`,
+			"Backslash": `C:\imds\mock`,
+			"Newline":   "line1\nline2",
+			"Unicode":   "日本語 🚀",
+		},
+	}
+
+	out, err := tagPatch.Patch([]byte(`{}`))
+	require.NoError(t, err)
+
+	var doc struct {
+		Tags struct {
+			Instance map[string]string `json:"instance"`
+		} `json:"tags"`
+	}
+	require.NoError(t, json.Unmarshal(out, &doc))
+	assert.Equal(t, tagPatch.Tags, doc.Tags.Instance)
+}
+
+func TestInstanceTagPatch_InvalidTag(t *testing.T) {
+	tagPatch := patch.InstanceTag{
+		Tags: map[string]string{
+			"Cost Centre": "1234",
+		},
+	}
+
+	in := []byte(`{"testing":"123"}`)
+	out, err := tagPatch.Patch(in)
+	require.EqualError(t, err, `invalid instance tag key "Cost Centre": character ' ' is not supported by IMDS, expecting letters, numbers or + - = . , _ : @`)
+	assert.Equal(t, in, out)
+}
+
+func TestValidateInstanceTags(t *testing.T) {
+	tags := map[string]string{
+		"Name":             "testing",
+		"a+b-c=d.e,f_g:h@": "punctuation",
+		"2023":             "",
+		strings.Repeat("k", patch.MaxTagKeyLength): strings.Repeat("v", patch.MaxTagValueLength),
+	}
+
+	assert.NoError(t, patch.ValidateInstanceTags(tags))
+}
+
+func TestValidateInstanceTags_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		tags map[string]string
+		err  string
+	}{
+		{
+			name: "EmptyKey",
+			tags: map[string]string{"": "value"},
+			err:  `invalid instance tag key "": key cannot be empty`,
+		},
+		{
+			name: "KeyTooLong",
+			tags: map[string]string{strings.Repeat("k", 129): "value"},
+			err:  `invalid instance tag key "` + strings.Repeat("k", 129) + `": 129 characters exceeds the maximum of 128`,
+		},
+		{
+			name: "ValueTooLong",
+			tags: map[string]string{"Name": strings.Repeat("v", 257)},
+			err:  `invalid instance tag value for key "Name": 257 characters exceeds the maximum of 256`,
+		},
+		{
+			name: "ReservedPrefix",
+			tags: map[string]string{"aws:cloudformation:stack-name": "stack"},
+			err:  `invalid instance tag key "aws:cloudformation:stack-name": the aws: prefix is reserved for use by AWS`,
+		},
+		{
+			name: "ReservedPrefixCaseInsensitive",
+			tags: map[string]string{"AWS:Name": "stack"},
+			err:  `invalid instance tag key "AWS:Name": the aws: prefix is reserved for use by AWS`,
+		},
+		{
+			name: "Slash",
+			tags: map[string]string{"app.kubernetes.io/name": "imds-mock"},
+			err:  `invalid instance tag key "app.kubernetes.io/name": character '/' is not supported by IMDS, expecting letters, numbers or + - = . , _ : @`,
+		},
+		{
+			name: "Wildcard",
+			tags: map[string]string{"a*": "value"},
+			err:  `invalid instance tag key "a*": character '*' is not supported by IMDS, expecting letters, numbers or + - = . , _ : @`,
+		},
+		{
+			name: "Period",
+			tags: map[string]string{".": "value"},
+			err:  `invalid instance tag key ".": key is reserved and cannot be exposed through IMDS`,
+		},
+		{
+			name: "Index",
+			tags: map[string]string{"_index": "value"},
+			err:  `invalid instance tag key "_index": key is reserved and cannot be exposed through IMDS`,
+		},
+		{
+			name: "FirstInKeyOrder",
+			tags: map[string]string{"b/c": "value", "a b": "value"},
+			err:  `invalid instance tag key "a b": character ' ' is not supported by IMDS, expecting letters, numbers or + - = . , _ : @`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, patch.ValidateInstanceTags(tt.tags), tt.err)
+		})
+	}
+}
+
+func TestValidateInstanceTags_TooMany(t *testing.T) {
+	tags := make(map[string]string, patch.MaxInstanceTags+1)
+	for i := 0; i <= patch.MaxInstanceTags; i++ {
+		tags[fmt.Sprintf("Tag%d", i)] = "value"
+	}
+
+	assert.EqualError(t, patch.ValidateInstanceTags(tags), "too many instance tags: 51 provided, a maximum of 50 is supported")
+}
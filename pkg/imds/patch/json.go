@@ -22,8 +22,32 @@ SOFTWARE.
 
 package patch
 
+import jsonpatch "github.com/evanphx/json-patch/v5"
+
 // JSONPatcher defines an interface for patching a JSON document
 type JSONPatcher interface {
 	// Patch a JSON document with any pre-configured JSON patch document
 	Patch(in []byte) ([]byte, error)
 }
+
+// JSON is used to patch a JSON document with a sequence of operations defined
+// within a JSON Patch document, see: https://www.rfc-editor.org/rfc/rfc6902
+type JSON struct {
+	Document []byte
+}
+
+// Patch the JSON document by applying each operation in sequence. The patch
+// is atomic and the JSON document will be left unchanged if any operation fails
+func (p JSON) Patch(in []byte) ([]byte, error) {
+	patch, err := jsonpatch.DecodePatch(p.Document)
+	if err != nil {
+		return in, err
+	}
+
+	out, err := patch.Apply(in)
+	if err != nil {
+		return in, err
+	}
+
+	return out, nil
+}
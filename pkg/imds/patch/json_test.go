@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPatch(t *testing.T) {
+	jsonPatch := patch.JSON{
+		Document: []byte(`[
+	{"op": "replace", "path": "/placement/region", "value": "eu-west-2"},
+	{"op": "remove", "path": "/profile"}
+]`),
+	}
+
+	out, err := jsonPatch.Patch([]byte(`{"placement":{"region":"us-east-1"},"profile":"default-hvm"}`))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"placement":{"region":"eu-west-2"}}`, string(out))
+}
+
+func TestJSONPatch_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+	}{
+		{
+			name:     "InvalidDocument",
+			document: `[`,
+		},
+		{
+			name:     "MissingPath",
+			document: `[{"op": "remove", "path": "/unknown"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonPatch := patch.JSON{Document: []byte(tt.document)}
+
+			out, err := jsonPatch.Patch([]byte(`{"testing":"123"}`))
+			require.Error(t, err)
+			assert.Equal(t, `{"testing":"123"}`, string(out))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package patch
+
+import (
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// Merge is used to deep merge a JSON document onto another using the semantics
+// of a JSON Merge Patch, see: https://www.rfc-editor.org/rfc/rfc7396. Any field
+// set to null within the merge document will be removed
+type Merge struct {
+	Document []byte
+}
+
+// Patch the JSON document by deep merging the configured document onto it
+func (p Merge) Patch(in []byte) ([]byte, error) {
+	out, err := jsonpatch.MergePatch(in, p.Document)
+	if err != nil {
+		return in, err
+	}
+
+	return out, nil
+}
@@ -24,6 +24,7 @@ package patch
 
 import (
 	"bytes"
+	"fmt"
 	"text/template"
 	"time"
 
@@ -46,22 +47,27 @@ const spotTemplate = `[
 			}{{ if .TerminationTime }},
 			"termination-time": "{{ .TerminationTime }}"{{ end }}
 		}
-	},
-	{
-		"op": "add",
-		"path": "/events",
-		"value": {
-			"recommendations": {
-				"rebalance": {
-					"noticeTime": "{{ .RebalanceTime }}"
-				}
+	}
+]`
+
+const rebalanceTemplate = `{
+	"events": {
+		"recommendations": {
+			"rebalance": {
+				"noticeTime": "{{ .NoticeTime }}"
 			}
 		}
 	}
-]`
+}`
+
+var (
+	spotPatch      = template.Must(template.New("SpotPatch").Parse(spotTemplate))
+	rebalancePatch = template.Must(template.New("RebalancePatch").Parse(rebalanceTemplate))
+)
 
-var spotPatch = template.Must(template.New("SpotPatch").
-	Parse(spotTemplate))
+// DefaultSpotLeadTime defines the amount of warning provided by a spot interruption
+// notice before the instance is interrupted
+const DefaultSpotLeadTime = 2 * time.Minute
 
 // SpotInstanceAction is used to represent the lifecycle event (or action) of a spot instance
 type SpotInstanceAction string
@@ -72,11 +78,30 @@ const (
 	HibernateSpotInstanceAction SpotInstanceAction = "hibernate"
 )
 
+// ParseSpotInstanceAction converts a string into a supported spot instance action
+func ParseSpotInstanceAction(action string) (SpotInstanceAction, error) {
+	switch spotAction := SpotInstanceAction(action); spotAction {
+	case TerminateSpotInstanceAction, StopSpotInstanceAction, HibernateSpotInstanceAction:
+		return spotAction, nil
+	}
+
+	return "", fmt.Errorf("%s is not a supported spot action expecting (%s, %s or %s)",
+		action, TerminateSpotInstanceAction, StopSpotInstanceAction, HibernateSpotInstanceAction)
+}
+
 // Spot is used to patch a JSON document and replicate the use and lifecycle of a spot EC2 instance.
 // The lifecycle of a spot instance is exposed through the use of an instance action category,
 // see: https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/spot-instance-termination-notices.html#instance-action-metadata
 type Spot struct {
 	InstanceAction SpotInstanceAction
+
+	// LeadTime controls how far in advance of the instance action the interruption
+	// notice is issued. By default DefaultSpotLeadTime is used
+	LeadTime time.Duration
+
+	// Immediate issues the interruption notice at the time of the instance action,
+	// ignoring any LeadTime. A hibernate action is always immediate
+	Immediate bool
 }
 
 // Patch the document based on the provided instance action. The resulting JSON
@@ -85,8 +110,12 @@ type Spot struct {
 func (p Spot) Patch(in []byte) ([]byte, error) {
 	// A spot interruption notice can be issued two minutes in advance during a best case scenario
 	nowTime := time.Now().UTC()
-	if p.InstanceAction != HibernateSpotInstanceAction {
-		nowTime = nowTime.Add(2 * time.Minute)
+	if p.InstanceAction != HibernateSpotInstanceAction && !p.Immediate {
+		lead := p.LeadTime
+		if lead == 0 {
+			lead = DefaultSpotLeadTime
+		}
+		nowTime = nowTime.Add(lead)
 	}
 
 	now := nowTime.Format(time.RFC3339)
@@ -95,11 +124,9 @@ func (p Spot) Patch(in []byte) ([]byte, error) {
 		Action          SpotInstanceAction
 		ActionTime      string
 		TerminationTime string
-		RebalanceTime   string
 	}{
-		Action:        p.InstanceAction,
-		ActionTime:    now,
-		RebalanceTime: now,
+		Action:     p.InstanceAction,
+		ActionTime: now,
 	}
 
 	if spotDetails.Action == TerminateSpotInstanceAction {
@@ -119,3 +146,64 @@ func (p Spot) Patch(in []byte) ([]byte, error) {
 
 	return out, nil
 }
+
+// SpotRebalance is used to patch a JSON document and replicate a rebalance recommendation
+// raised against a spot EC2 instance at an elevated risk of interruption, see:
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/rebalance-recommendations.html
+type SpotRebalance struct{}
+
+// Patch the document by exposing a rebalance recommendation with the current time. Any
+// existing events, such as scheduled maintenance, will be retained
+func (p SpotRebalance) Patch(in []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	rebalancePatch.Execute(&buf, struct{ NoticeTime string }{
+		NoticeTime: time.Now().UTC().Format(time.RFC3339),
+	})
+
+	out, err := jsonpatch.MergePatch(in, buf.Bytes())
+	if err != nil {
+		return in, err
+	}
+
+	return out, nil
+}
+
+// SpotLifeCycle is used to patch a JSON document and replicate a spot EC2 instance
+// that has not been issued an interruption notice
+type SpotLifeCycle struct{}
+
+// Patch the document by switching the instance life cycle to spot. No spot categories
+// will be exposed within the IMDS metadata
+func (p SpotLifeCycle) Patch(in []byte) ([]byte, error) {
+	patch, _ := jsonpatch.DecodePatch([]byte(`[{"op": "replace", "path": "/instance-life-cycle", "value": "spot"}]`))
+
+	out, err := patch.Apply(in)
+	if err != nil {
+		return in, err
+	}
+
+	return out, nil
+}
+
+// SpotWithdraw is used to patch a JSON document and withdraw any interruption notice
+// previously raised against a spot EC2 instance
+type SpotWithdraw struct{}
+
+// Patch the document by removing all spot categories along with any rebalance
+// recommendation. The instance will remain a spot instance
+func (p SpotWithdraw) Patch(in []byte) ([]byte, error) {
+	patch, _ := jsonpatch.DecodePatch([]byte(`[
+	{"op": "remove", "path": "/spot"},
+	{"op": "remove", "path": "/events/recommendations"}
+]`))
+
+	opts := jsonpatch.NewApplyOptions()
+	opts.AllowMissingPathOnRemove = true
+
+	out, err := patch.ApplyWithOptions(in, opts)
+	if err != nil {
+		return in, err
+	}
+
+	return out, nil
+}
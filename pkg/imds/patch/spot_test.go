@@ -43,7 +43,12 @@ type spotPatchJSON struct {
 
 		TerminationTime *time.Time `json:"termination-time"`
 	} `json:"spot"`
+}
+
+// Only used for serialising the rebalance patch into a struct for assertions
+type rebalancePatchJSON struct {
 	Events struct {
+		Maintenance     map[string]interface{} `json:"maintenance"`
 		Recommendations struct {
 			Rebalance struct {
 				NoticeTime time.Time `json:"noticeTime"`
@@ -98,12 +103,95 @@ func TestSpotPatch(t *testing.T) {
 			} else {
 				require.Nil(t, spotJSON.Spot.TerminationTime)
 			}
+		})
+	}
+}
+
+func TestSpotPatch_LeadTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    patch.SpotInstanceAction
+		lead      time.Duration
+		immediate bool
+		duration  time.Duration
+	}{
+		{
+			name:     "StopAction",
+			action:   patch.StopSpotInstanceAction,
+			lead:     30 * time.Second,
+			duration: 30 * time.Second,
+		},
+		{
+			name:     "ZeroLeadTimeUsesDefault",
+			action:   patch.TerminateSpotInstanceAction,
+			lead:     0,
+			duration: patch.DefaultSpotLeadTime,
+		},
+		{
+			name:      "Immediate",
+			action:    patch.TerminateSpotInstanceAction,
+			lead:      30 * time.Second,
+			immediate: true,
+			duration:  0,
+		},
+		{
+			name:     "HibernateActionIgnoresLeadTime",
+			action:   patch.HibernateSpotInstanceAction,
+			lead:     30 * time.Second,
+			duration: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spotPatch := patch.Spot{
+				InstanceAction: tt.action,
+				LeadTime:       tt.lead,
+				Immediate:      tt.immediate,
+			}
+
+			out, err := spotPatch.Patch([]byte(`{"instance-life-cycle":"on-demand"}`))
+			require.NoError(t, err)
 
-			assert.WithinDuration(t, now, spotJSON.Events.Recommendations.Rebalance.NoticeTime, 1*time.Second)
+			var spotJSON spotPatchJSON
+			json.Unmarshal(out, &spotJSON)
+
+			now := time.Now().UTC().Add(tt.duration)
+			assert.WithinDuration(t, now, spotJSON.Spot.InstanceAction.ActionTime, 1*time.Second)
 		})
 	}
 }
 
+func TestSpotPatch_NoRebalanceRecommendation(t *testing.T) {
+	out, err := patch.Spot{InstanceAction: patch.StopSpotInstanceAction}.
+		Patch([]byte(`{"instance-life-cycle":"on-demand"}`))
+
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "events")
+}
+
+func TestSpotRebalancePatch(t *testing.T) {
+	out, err := patch.SpotRebalance{}.Patch([]byte(`{"events":{"maintenance":{"history":[],"scheduled":[]}}}`))
+	require.NoError(t, err)
+
+	var rebalanceJSON rebalancePatchJSON
+	json.Unmarshal(out, &rebalanceJSON)
+
+	assert.Contains(t, rebalanceJSON.Events.Maintenance, "scheduled")
+	assert.WithinDuration(t, time.Now().UTC(), rebalanceJSON.Events.Recommendations.Rebalance.NoticeTime, 1*time.Second)
+}
+
+func TestSpotRebalancePatch_NoEvents(t *testing.T) {
+	out, err := patch.SpotRebalance{}.Patch([]byte(`{"instance-life-cycle":"spot"}`))
+
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "noticeTime")
+}
+
+func TestSpotRebalancePatch_InvalidInputJSON(t *testing.T) {
+	_, err := patch.SpotRebalance{}.Patch([]byte(`{`))
+	require.Error(t, err)
+}
+
 func TestSpotPatch_InvalidInputJSON(t *testing.T) {
 	spotPatch := patch.Spot{
 		InstanceAction: patch.HibernateSpotInstanceAction,
@@ -112,3 +200,40 @@ func TestSpotPatch_InvalidInputJSON(t *testing.T) {
 	_, err := spotPatch.Patch([]byte(`{`))
 	require.Error(t, err)
 }
+
+func TestParseSpotInstanceAction(t *testing.T) {
+	action, err := patch.ParseSpotInstanceAction("stop")
+
+	require.NoError(t, err)
+	assert.Equal(t, patch.StopSpotInstanceAction, action)
+}
+
+func TestParseSpotInstanceAction_Unsupported(t *testing.T) {
+	_, err := patch.ParseSpotInstanceAction("reboot")
+	require.EqualError(t, err, "reboot is not a supported spot action expecting (terminate, stop or hibernate)")
+}
+
+func TestSpotLifeCycle(t *testing.T) {
+	out, err := patch.SpotLifeCycle{}.Patch([]byte(`{"instance-life-cycle":"on-demand"}`))
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"instance-life-cycle":"spot"}`, string(out))
+}
+
+func TestSpotWithdraw(t *testing.T) {
+	in, err := patch.Spot{InstanceAction: patch.StopSpotInstanceAction}.
+		Patch([]byte(`{"instance-life-cycle":"on-demand","events":{"recommendations":{}}}`))
+	require.NoError(t, err)
+
+	out, err := patch.SpotWithdraw{}.Patch(in)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"instance-life-cycle":"spot","events":{}}`, string(out))
+}
+
+func TestSpotWithdraw_NoInterruptionNotice(t *testing.T) {
+	out, err := patch.SpotWithdraw{}.Patch([]byte(`{"instance-life-cycle":"spot"}`))
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"instance-life-cycle":"spot"}`, string(out))
+}
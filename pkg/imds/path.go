@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Resolves a slash separated category path into a gjson path that addresses each
+// key literally, escaping any gjson metacharacter such as a '.', '*' or '?'. As a
+// key can contain a slash, the longest matching key is resolved at each level of
+// the document. Elements of an array of categories are addressed by index. A flag
+// is returned indicating whether the category exists
+//
+//	tags/instance/app.kubernetes.io/name
+//	tags.instance.app\.kubernetes\.io/name
+func resolvePath(json []byte, categoryPath string) (string, bool) {
+	if categoryPath == "" {
+		return "", true
+	}
+
+	segments := strings.Split(categoryPath, "/")
+	doc := gjson.ParseBytes(json)
+
+	path := ""
+	for i := 0; i < len(segments); {
+		if isObjectArray(doc) {
+			// Only arrays of categories support index-style addressing
+			index, err := strconv.Atoi(segments[i])
+			if err != nil || index < 0 || segments[i] != strconv.Itoa(index) {
+				return "", false
+			}
+
+			res := doc.Get(segments[i])
+			if !res.Exists() {
+				return "", false
+			}
+
+			path = joinPath(path, segments[i])
+			doc = res
+			i++
+			continue
+		}
+
+		if !doc.IsObject() {
+			return "", false
+		}
+
+		matched := false
+		for j := len(segments); j > i; j-- {
+			key := gjson.Escape(strings.Join(segments[i:j], "/"))
+			if res := doc.Get(key); res.Exists() {
+				path = joinPath(path, key)
+				doc = res
+				i = j
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return "", false
+		}
+	}
+
+	return path, true
+}
+
+// Appends an escaped key to a gjson path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// A parent category lists its keys rather than returning a value. Either a JSON
+// object, or an array of JSON objects addressed by index, such as public-keys
+func isParent(res gjson.Result) bool {
+	return res.IsObject() || isObjectArray(res)
+}
+
+func isObjectArray(res gjson.Result) bool {
+	if !res.IsArray() {
+		return false
+	}
+
+	elements := res.Array()
+	for _, element := range elements {
+		if !element.IsObject() {
+			return false
+		}
+	}
+
+	return len(elements) > 0
+}
+
+// Renders the value of a category the way IMDS does. Multi-value categories,
+// such as local-ipv4s or security-groups, are returned as a newline separated list
+func render(res gjson.Result) string {
+	if !res.IsArray() {
+		return res.String()
+	}
+
+	values := make([]string, 0, len(res.Array()))
+	for _, value := range res.Array() {
+		values = append(values, value.String())
+	}
+
+	return strings.Join(values, "\n")
+}
@@ -23,9 +23,11 @@ SOFTWARE.
 package imds
 
 import (
+	"context"
 	_ "embed"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -33,8 +35,14 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/audit"
 	"github.com/purpleclay/imds-mock/pkg/imds/cache"
 	"github.com/purpleclay/imds-mock/pkg/imds/event"
+	"github.com/purpleclay/imds-mock/pkg/imds/har"
+	"github.com/purpleclay/imds-mock/pkg/imds/iam"
+	"github.com/purpleclay/imds-mock/pkg/imds/journal"
+	"github.com/purpleclay/imds-mock/pkg/imds/logging"
+	"github.com/purpleclay/imds-mock/pkg/imds/metrics"
 	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
 	"github.com/purpleclay/imds-mock/pkg/imds/patch"
 	"github.com/purpleclay/imds-mock/pkg/imds/token"
@@ -75,11 +83,16 @@ const (
 //go:embed on-demand.json
 var onDemandInstance []byte
 
+// Swapped out during testing
+var newScheduler = event.NewScheduler
+
 // Really crude attempt to protect a byte array from concurrency issues during
-// event driven patches
+// event driven patches. Every patch increments the generation of the document,
+// invalidating any response cached against an older generation
 type patchedJSON struct {
-	data []byte
-	mu   sync.RWMutex
+	data       []byte
+	generation uint64
+	mu         sync.RWMutex
 }
 
 func (p *patchedJSON) Bytes() []byte {
@@ -90,13 +103,26 @@ func (p *patchedJSON) Bytes() []byte {
 	return copy
 }
 
+func (p *patchedJSON) Generation() uint64 {
+	p.mu.RLock()
+	generation := p.generation
+	p.mu.RUnlock()
+
+	return generation
+}
+
 func (p *patchedJSON) Patch(patcher patch.JSONPatcher) error {
 	p.mu.Lock()
-	var err error
-	p.data, err = patcher.Patch(p.data)
-	p.mu.Unlock()
+	defer p.mu.Unlock()
 
-	return err
+	data, err := patcher.Patch(p.data)
+	if err != nil {
+		return err
+	}
+
+	p.data = data
+	p.generation++
+	return nil
 }
 
 // Options provides a set of options for configuring the behaviour
@@ -119,6 +145,11 @@ type Options struct {
 	// exposed as instance tags through the IMDS mock
 	InstanceTags map[string]string
 
+	// TokenSecret pins the secret used to sign IMDSv2 session tokens, ensuring
+	// tokens remain valid across restarts. By default a random secret is
+	// generated for each process
+	TokenSecret string
+
 	// Port controls the port that is used by the IMDS mock. By default
 	// it will use port 1338
 	Port int
@@ -136,6 +167,118 @@ type Options struct {
 	// simulating a spot instance. By default the spot interruption will be
 	// immediate, but can be delayed by a pre-configured interval
 	SpotAction SpotActionEvent
+
+	// UserData contains the raw bytes that will be served as user data through
+	// the IMDS mock. Binary payloads, such as gzip compressed scripts, will be
+	// returned unchanged. By default no user data exists and a 404 is returned
+	UserData []byte
+
+	// IdentityKey contains a PEM encoded RSA private key used for signing the
+	// instance identity document. By default a key pair will be generated
+	IdentityKey []byte
+
+	// IdentityCertificate contains the PEM encoded certificate associated with
+	// the IdentityKey. Clients should verify any signature using this certificate
+	IdentityCertificate []byte
+
+	// IAMRole defines the name of the IAM role attached to the instance through
+	// its instance profile. Temporary security credentials will be exposed using
+	// this name. By default the ssm-access role is used
+	IAMRole string
+
+	// ExcludeIAM controls if the IMDS mock excludes all IAM categories from its
+	// supported list of metadata categories, simulating an instance without an
+	// instance profile
+	ExcludeIAM bool
+
+	// InstanceProfileArn defines the ARN of the instance profile exposed through
+	// the iam/info category. By default it will be derived from the IAM role
+	InstanceProfileArn string
+
+	// CredentialsTTL controls the lifetime of any temporary security credentials.
+	// New credentials are issued ahead of the existing ones expiring. A negative
+	// lifetime is rejected. By default credentials will expire after six hours
+	CredentialsTTL time.Duration
+
+	// Metadata contains a JSON document that replaces the embedded on-demand
+	// instance metadata served by the IMDS mock. The document is validated
+	// during startup
+	Metadata []byte
+
+	// MergeMetadata controls if the Metadata document is deep merged onto the
+	// embedded on-demand instance metadata rather than replacing it. Setting
+	// a field to null within the Metadata document will remove it
+	MergeMetadata bool
+
+	// SpotNoticeLead controls how far in advance of the spot instance being
+	// interrupted the interruption notice is raised. A negative lead is rejected.
+	// By default two minutes of warning will be given
+	SpotNoticeLead time.Duration
+
+	// SpotNoticeImmediate raises the interruption notice at the time the spot
+	// instance is interrupted, ignoring the SpotNoticeLead. A hibernate
+	// interruption is always immediate
+	SpotNoticeImmediate bool
+
+	// SpotRebalanceLead controls how far in advance of the interruption notice
+	// a rebalance recommendation is raised. By default the recommendation will
+	// be raised at the same time as the interruption notice
+	SpotRebalanceLead time.Duration
+
+	// SpotTermination controls how the IMDS mock behaves once the time of a
+	// terminate or stop interruption notice has passed, replicating an instance
+	// that no longer exists. By default the IMDS mock will continue to serve
+	// requests
+	SpotTermination TerminationMode
+
+	// SpotNoNotice controls if a simulated spot instance starts without an
+	// interruption notice. A notice can then be raised at any time through the
+	// admin API, ignoring the SpotAction
+	SpotNoNotice bool
+
+	// JournalSize controls the maximum number of requests recorded within the
+	// journal of the IMDS mock. Once full, the oldest request is discarded. By
+	// default the most recent 1000 requests are recorded
+	JournalSize int
+
+	// AuditV1 enables an audit mode that serves IMDSv1 requests as normal, but
+	// logs and counts them separately by both path and user agent. Ideal for
+	// identifying any code path that will break once IMDSv2 is enforced. A
+	// summary report is logged on shutdown. Ignored if IMDSv2 is enforced
+	AuditV1 bool
+
+	// AdminPort controls the port used by the admin API, which supports the
+	// patching of instance metadata at runtime. By default the admin API is
+	// disabled and will only be started if a port is provided
+	AdminPort int
+
+	// LogLevel controls the minimum level of any log entry written by the IMDS
+	// mock, either debug, info, warn, error or off. By default info is used
+	LogLevel string
+
+	// LogFormat controls if log entries are written as JSON or human-readable
+	// console output. By default JSON is used
+	LogFormat logging.Format
+
+	// LogFile defines the path of a file that all log entries are appended to.
+	// By default log entries are written to stderr. Session tokens and IAM
+	// secret material are always redacted
+	LogFile string
+
+	// UsageReport controls if a report of every metadata category accessed
+	// through the IMDS mock is logged on shutdown. A report is always available
+	// through the admin API
+	UsageReport bool
+
+	// HARFile defines the path of a file that the most recent request and response
+	// pairs handled by the IMDS mock are written to as an HTTP Archive (HAR) when the
+	// mock is closed. Traffic is not redacted. By default no archive is written
+	HARFile string
+
+	// HARSize controls the maximum number of request and response pairs retained
+	// for the HAR file. Once reached, the oldest pair is discarded for every new
+	// request handled
+	HARSize int
 }
 
 // SpotActionEvent defines a spot interruption event
@@ -160,15 +303,35 @@ var DefaultOptions = Options{
 		Action:   patch.TerminateSpotInstanceAction,
 		Duration: 0 * time.Second,
 	},
+	SpotNoticeLead:    patch.DefaultSpotLeadTime,
+	SpotRebalanceLead: 0 * time.Second,
+	SpotTermination:   NoTermination,
+	JournalSize:       journal.DefaultCapacity,
+	HARSize:           har.DefaultCapacity,
+	IAMRole:           iam.DefaultRole,
+	CredentialsTTL:    iam.DefaultCredentialsTTL,
+	LogLevel:          "info",
+	LogFormat:         logging.JSONFormat,
 }
 
 // Used as a hashset for quick lookups. Any matched path will just return its value
 // and not be used to perform a key lookup
-var reservedPaths = map[string]struct{}{
-	"iam.info":                            {},
-	"iam.security-credentials.ssm-access": {},
-	"spot.instance-action":                {},
-	"events.recommendations.rebalance":    {},
+type reservedPaths map[string]struct{}
+
+func newReservedPaths(opts Options) reservedPaths {
+	paths := reservedPaths{
+		"iam.info":                         {},
+		"spot.instance-action":             {},
+		"events.recommendations.rebalance": {},
+		"events.maintenance.history":       {},
+		"events.maintenance.scheduled":     {},
+	}
+
+	if !opts.ExcludeIAM {
+		paths["iam.security-credentials."+gjson.Escape(opts.IAMRole)] = struct{}{}
+	}
+
+	return paths
 }
 
 // Serve configures the IMDS mock using default options to handle HTTP requests
@@ -180,96 +343,454 @@ func Serve() (*gin.Engine, error) {
 // ServeWith configures the IMDS mock based on the incoming options to handle HTTP requests
 // in the exact same way as the IMDS service accessible from any EC2 instance
 func ServeWith(opts Options) (*gin.Engine, error) {
-	r := gin.New()
-	injectGlobalMiddleware(r, opts)
+	m, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	// see: https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies
-	r.SetTrustedProxies(nil)
+	if opts.AutoStart {
+		// Blocks until the IMDS mock stops serving requests
+		srv := &Server{mock: m}
+		if err = srv.Start(context.Background()); err != nil {
+			m.Close()
+			return nil, err
+		}
+		err = srv.wait()
+	}
+
+	return m.router, err
+}
 
-	// Locally managed cache
-	memcache := cache.New()
+// NewHandler configures the IMDS mock based on the incoming options, returning a handler
+// that can be mounted within any net/http server, such as an http.ServeMux or httptest.Server.
+// The mock is never started, regardless of the AutoStart option. The returned function
+// must be called once the handler is no longer in use, cancelling all scheduled events,
+// such as the rotation of temporary security credentials, and closing the logger
+func NewHandler(opts Options) (http.Handler, func(), error) {
+	m, err := New(opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Manage the patching of the underlying JSON that is served by the IMDS mock
-	mockResponse := patchedJSON{data: onDemandInstance}
+	return m.Handler(), m.Close, nil
+}
 
-	if !opts.ExcludeInstanceTags {
-		if err := mockResponse.Patch(patch.InstanceTag{Tags: opts.InstanceTags}); err != nil {
+// Mock defines a configured IMDS mock. It provides access to the routers that
+// handle both IMDS and admin requests, sharing the same underlying instance metadata
+type Mock struct {
+	opts        Options
+	router      *gin.Engine
+	admin       *gin.Engine
+	metadata    *patchedJSON
+	cache       *cache.MemCache
+	events      *event.Scheduler
+	termination *termination
+	journal     *journal.Journal
+	har         *har.Archive
+	tokens      *token.Store
+	metrics     *metrics.Metrics
+	audit       *audit.V1
+	usage       *audit.Usage
+	logger      *zap.Logger
+	closeLogger func()
+	closeOnce   sync.Once
+}
+
+// New configures the IMDS mock based on the incoming options, without starting it. All
+// IMDS requests will be handled in the exact same way as the IMDS service accessible
+// from any EC2 instance
+func New(opts Options) (_ *Mock, err error) {
+	if opts.IAMRole == "" {
+		opts.IAMRole = iam.DefaultRole
+	}
+
+	if opts.CredentialsTTL == 0 {
+		opts.CredentialsTTL = iam.DefaultCredentialsTTL
+	}
+
+	m := &Mock{
+		opts: opts,
+		// Manage the patching of the underlying JSON that is served by the IMDS mock
+		metadata: &patchedJSON{data: onDemandInstance},
+		// Track all scheduled events, so they can be cancelled
+		events: newScheduler(),
+		// Spot instances can be terminated after being interrupted
+		termination: newTermination(opts.SpotTermination),
+		// Record every request handled by the mock
+		journal: journal.New(opts.JournalSize),
+		// Issues, verifies and revokes all IMDSv2 session tokens
+		tokens: token.NewStore(token.NewSigner([]byte(opts.TokenSecret))),
+		// Prometheus metrics exposed through the admin API
+		metrics: metrics.New(),
+		// Aggregate the categories accessed through the mock
+		usage: audit.NewUsage(),
+	}
+
+	// Locally managed cache, invalidated by any patch to the metadata
+	m.cache = cache.New(m.metadata)
+
+	if opts.HARFile != "" {
+		m.har = har.New(opts.HARSize)
+	}
+
+	// Ensure any report or archive is written before the process exits
+	m.termination.onExit = m.Close
+
+	// Nothing is reported or archived, as the mock never handled a request
+	defer func() {
+		if err != nil {
+			m.release()
+		}
+	}()
+
+	if len(opts.Metadata) > 0 {
+		if err := loadMetadata(m.metadata, opts); err != nil {
 			return nil, err
 		}
 	}
 
-	// Event based patching of spot instance
+	// Instance tags are merged with any defined within custom metadata, which take precedence
+	var tagPatch patch.JSONPatcher = patch.InstanceTag{Tags: undefinedTags(m.metadata.Bytes(), opts.InstanceTags)}
+	if opts.ExcludeInstanceTags {
+		tagPatch = patch.ExcludeInstanceTags{}
+	}
+
+	if err := m.metadata.Patch(tagPatch); err != nil {
+		return nil, err
+	}
+
+	// Temporary security credentials are rotated ahead of them expiring
+	if err := m.exposeIAMRole(); err != nil {
+		return nil, err
+	}
+
+	logger, closeLogger, err := logging.New(logging.Options{
+		Level:  opts.LogLevel,
+		Format: opts.LogFormat,
+		File:   opts.LogFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.logger = logger
+	m.closeLogger = closeLogger
+
+	// IMDSv1 requests cannot be made when IMDSv2 is enforced
+	if opts.AuditV1 && !opts.IMDSv2 {
+		m.audit = audit.NewV1()
+	}
+
+	if m.router, err = m.imdsRouter(logger); err != nil {
+		return nil, err
+	}
+	m.admin = m.adminRouter(logger)
+
+	// Event based patching of spot instance. Scheduled once the mock is fully
+	// initialised, as an interruption could immediately terminate it
 	if opts.Spot {
-		if opts.SpotAction.Duration > 0 {
-			event.Once(opts.SpotAction.Duration, func() {
-				if patchErr := mockResponse.Patch(patch.Spot{InstanceAction: opts.SpotAction.Action}); patchErr != nil {
-					return
-				}
-
-				// Invalidate the cache to ensure the mock returns the new spot instance categories
-				memcache.Remove("/latest/meta-data", "/latest/meta-data/")
-			})
-		} else {
-			if err := mockResponse.Patch(patch.Spot{InstanceAction: opts.SpotAction.Action}); err != nil {
+		if opts.SpotNoticeLead < 0 {
+			return nil, errors.New("spot notice lead cannot be negative")
+		}
+
+		if err := m.metadata.Patch(patch.SpotLifeCycle{}); err != nil {
+			return nil, err
+		}
+
+		// An interruption notice can still be raised through the admin API
+		if !opts.SpotNoNotice {
+			if err := m.scheduleSpotInterruption(); err != nil {
 				return nil, err
 			}
 		}
 	}
 
+	return m, nil
+}
+
+// Router returns the router that handles all IMDS requests
+func (m *Mock) Router() *gin.Engine {
+	return m.router
+}
+
+// Journal returns the journal of all requests handled by the mock
+func (m *Mock) Journal() *journal.Journal {
+	return m.journal
+}
+
+// HAR returns the archive of all request and response pairs handled by the mock.
+// If no HAR file has been configured, nil is returned
+func (m *Mock) HAR() *har.Archive {
+	return m.har
+}
+
+// Handler returns a handler for all IMDS requests, with all middleware applied
+func (m *Mock) Handler() http.Handler {
+	return m.router
+}
+
+// AdminHandler returns a handler for all requests to the admin API
+func (m *Mock) AdminHandler() http.Handler {
+	return m.admin
+}
+
+// AdminRouter returns the router that handles all requests to the admin API
+func (m *Mock) AdminRouter() *gin.Engine {
+	return m.admin
+}
+
+// Patch the instance metadata served by the mock. All cached instance categories
+// are invalidated, ensuring the mock returns the patched metadata
+func (m *Mock) Patch(patcher patch.JSONPatcher) error {
+	return m.metadata.Patch(patcher)
+}
+
+// Raises a rebalance recommendation ahead of the spot interruption notice. Any event
+// due at startup is raised immediately
+func (m *Mock) scheduleSpotInterruption() error {
+	noticeAt := m.opts.SpotAction.Duration
+	rebalanceAt := noticeAt - m.opts.SpotRebalanceLead
+	if rebalanceAt < 0 {
+		rebalanceAt = 0
+	}
+
+	if err := m.raiseAt(rebalanceAt, m.RecommendSpotRebalance); err != nil {
+		return err
+	}
+
+	return m.raiseAt(noticeAt, func() error {
+		return m.InterruptSpot(m.opts.SpotAction.Action)
+	})
+}
+
+func (m *Mock) raiseAt(delay time.Duration, raise func() error) error {
+	if delay <= 0 {
+		return raise()
+	}
+
+	m.events.Once(delay, func() {
+		raise() // nolint: errcheck
+	})
+	return nil
+}
+
+// RecommendSpotRebalance raises a rebalance recommendation, signalling that the spot
+// instance is at an elevated risk of interruption. If the mock is not simulating a
+// spot instance, it will become one
+func (m *Mock) RecommendSpotRebalance() error {
+	if err := m.metadata.Patch(patch.SpotLifeCycle{}); err != nil {
+		return err
+	}
+
+	if err := m.metadata.Patch(patch.SpotRebalance{}); err != nil {
+		return err
+	}
+	m.metrics.EventFired("spot-rebalance")
+
+	return nil
+}
+
+// InterruptSpot raises a spot interruption notice with the given instance action,
+// replacing any existing notice. A rebalance recommendation will be raised alongside
+// the notice if one does not exist. If the mock is not simulating a spot instance,
+// it will become one
+func (m *Mock) InterruptSpot(action patch.SpotInstanceAction) error {
+	if !gjson.GetBytes(m.metadata.Bytes(), "events.recommendations.rebalance").Exists() {
+		if err := m.metadata.Patch(patch.SpotRebalance{}); err != nil {
+			return err
+		}
+	}
+
+	if err := m.metadata.Patch(patch.Spot{
+		InstanceAction: action,
+		LeadTime:       m.opts.SpotNoticeLead,
+		Immediate:      m.opts.SpotNoticeImmediate,
+	}); err != nil {
+		return err
+	}
+	m.termination.schedule(m.metadata.Bytes())
+	m.metrics.EventFired("spot-interruption")
+
+	return nil
+}
+
+// WithdrawSpotInterruption withdraws any raised spot interruption notice. All spot
+// categories will no longer be accessible through the mock, and any pending
+// termination of the instance is cancelled
+func (m *Mock) WithdrawSpotInterruption() error {
+	if err := m.metadata.Patch(patch.SpotWithdraw{}); err != nil {
+		return err
+	}
+	m.termination.cancel()
+	m.metrics.EventFired("spot-withdrawal")
+
+	return nil
+}
+
+// Close cancels all scheduled events, such as the rotation of temporary security
+// credentials, along with any pending termination of a spot instance. Any request
+// held open by the mock will be released. If auditing IMDSv1 requests, a summary
+// report will be logged. If configured, a category usage report will be logged and
+// all traffic written to a HAR file, before the logger is flushed and closed
+func (m *Mock) Close() {
+	m.closeOnce.Do(func() {
+		m.events.Stop()
+		m.termination.stop()
+
+		if m.audit != nil {
+			report := m.audit.Report()
+			m.logger.Info("IMDSv1 audit report",
+				zap.Int("metadataNoToken", report.MetadataNoToken),
+				zap.Any("requests", report.Requests))
+		}
+
+		if m.opts.UsageReport {
+			m.logger.Info("category usage report", zap.Any("categories", m.usage.Report().Categories))
+		}
+
+		if m.har != nil {
+			if err := m.har.WriteFile(m.opts.HARFile); err != nil {
+				m.logger.Error("failed to write HAR file", zap.String("file", m.opts.HARFile), zap.Error(err))
+			}
+		}
+
+		m.closeLogger()
+	})
+}
+
+// Releases all scheduled events, held requests and the logger, without writing
+// any report or archive
+func (m *Mock) release() {
+	m.closeOnce.Do(func() {
+		m.events.Stop()
+		m.termination.stop()
+
+		if m.closeLogger != nil {
+			m.closeLogger()
+		}
+	})
+}
+
+// Reboot simulates an instance reboot, revoking every IMDSv2 session token issued
+// beforehand. Clients must request a new session token to continue
+func (m *Mock) Reboot() {
+	revoked := m.tokens.RevokeAll()
+	m.metrics.EventFired("reboot")
+	m.logger.Info("instance rebooted, session tokens revoked", zap.Int("revoked", revoked))
+}
+
+// Tokens returns the store of all IMDSv2 session tokens issued by the mock
+func (m *Mock) Tokens() *token.Store {
+	return m.tokens
+}
+
+// Usage returns the aggregate of all metadata categories accessed through the mock
+func (m *Mock) Usage() *audit.Usage {
+	return m.usage
+}
+
+// V1Audit returns the audit of all IMDSv1 requests handled by the mock. If
+// auditing is not enabled, nil is returned
+func (m *Mock) V1Audit() *audit.V1 {
+	return m.audit
+}
+
+func (m *Mock) imdsRouter(logger *zap.Logger) (*gin.Engine, error) {
+	opts := m.opts
+
+	r := gin.New()
+	// A terminated instance never responds, so its requests must not be recorded
+	r.Use(m.termination.middleware())
+	r.Use(middleware.Journal(m.journal, m.tokens), middleware.Metrics(m.metrics), middleware.Usage(m.usage))
+	var zapOpts []middleware.ZapOption
+	if m.har != nil {
+		zapOpts = append(zapOpts, middleware.WithHAR(m.har))
+	}
+	injectGlobalMiddleware(r, opts, logger, zapOpts...)
+
+	// see: https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies
+	r.SetTrustedProxies(nil)
+
+	// Used for signing the instance identity document
+	signer, err := newLazySigner(opts)
+	if err != nil {
+		return nil, err
+	}
+	launched := time.Now()
+
 	// Determine the type of auth for each endpoint
-	authMiddleware := selectAuthMiddleware(opts)
+	authMiddleware := selectAuthMiddleware(opts, m.tokens, m.audit, logger)
+
+	// Categories that return JSON rather than a list of keys
+	reserved := newReservedPaths(opts)
+
+	cached := middleware.Cache(m.cache, middleware.WithCacheMetrics(m.metrics))
 
-	r.GET("/latest/meta-data", authMiddleware, middleware.Cache(memcache), func(c *gin.Context) {
-		c.String(http.StatusOK, keys(mockResponse.Bytes(), ""))
+	r.GET("/latest/meta-data", authMiddleware, cached, func(c *gin.Context) {
+		c.String(http.StatusOK, keys(m.metadata.Bytes(), "", reserved))
 	})
 
-	r.GET("/latest/meta-data/*category", authMiddleware, middleware.Cache(memcache), func(c *gin.Context) {
+	r.GET("/latest/meta-data/*category", authMiddleware, cached, func(c *gin.Context) {
 		categoryPath := c.Param("category")
 		if categoryPath == "/" {
 			// Exact same behaviour as /latest/meta-data
-			c.String(http.StatusOK, keys(mockResponse.Bytes(), ""))
+			c.String(http.StatusOK, keys(m.metadata.Bytes(), "", reserved))
 			return
 		}
-
 		// Convert param into gjson path query
-		categoryPath = strings.TrimSuffix(categoryPath, "/")
-		categoryPath = strings.ReplaceAll(categoryPath, "/", ".")[1:]
+		metadata := m.metadata.Bytes()
+		categoryPath, found := resolvePath(metadata, strings.Trim(categoryPath, "/"))
 
 		// The IMDS service returns a 404 when attempting to query a field within a JSON instance category
-		if isReservedPathChild(categoryPath) {
+		if !found || reserved.isChild(categoryPath) {
 			c.Writer.Header().Add("Content-Type", "text/html")
 			c.String(http.StatusNotFound, notFound)
 			return
 		}
 
-		res := gjson.GetBytes(mockResponse.Bytes(), categoryPath)
+		res := gjson.GetBytes(metadata, categoryPath)
+
+		c.Writer.Header().Add("Content-Type", "text/plain")
 
-		if !res.Exists() {
+		switch {
+		case reserved.contains(categoryPath):
+			c.String(http.StatusOK, res.String())
+		case isParent(res):
+			// If the path returns a JSON object, then return a set of keys
+			c.String(http.StatusOK, keys(metadata, categoryPath, reserved))
+		default:
+			c.String(http.StatusOK, render(res))
+		}
+	})
+
+	r.GET("/latest/user-data", authMiddleware, middleware.RawResponse(), func(c *gin.Context) {
+		// The IMDS service returns a 404 if no user data was provided at launch
+		if len(opts.UserData) == 0 {
 			c.Writer.Header().Add("Content-Type", "text/html")
 			c.String(http.StatusNotFound, notFound)
 			return
 		}
 
-		c.Writer.Header().Add("Content-Type", "text/plain")
+		c.Data(http.StatusOK, "application/octet-stream", opts.UserData)
+	})
 
-		// If the path returns a JSON object, then return a set of keys
-		if res.IsObject() && notReservedPath(categoryPath) {
-			c.String(http.StatusOK, keys(mockResponse.Bytes(), categoryPath))
-		} else {
-			c.String(http.StatusOK, res.String())
-		}
+	r.GET("/latest/dynamic", authMiddleware, func(c *gin.Context) {
+		c.Writer.Header().Add("Content-Type", "text/plain")
+		c.String(http.StatusOK, dynamicCategories)
 	})
 
+	// Signed documents must be served exactly as they were signed
+	r.GET("/latest/dynamic/*category", authMiddleware, middleware.RawResponse(), dynamicHandler(m.metadata, signer, launched))
+
 	// Don't protect the token endpoint with any auth middleware
 	r.PUT("/latest/api/token", func(c *gin.Context) {
 		ttl, err := strconv.Atoi(c.Request.Header.Get(V2TokenTTLHeader))
 
 		if err == nil && (ttl > 0 && ttl <= token.MaxTTLInSeconds) {
-			tkn := token.NewV2(ttl)
-			out, _ := json.Marshal(&tkn)
+			tkn := m.tokens.Issue(ttl)
+			m.metrics.TokenIssued()
 
 			c.Writer.Header().Add("Content-Type", "text/plain")
-			c.String(http.StatusOK, base64.StdEncoding.EncodeToString(out))
+			c.String(http.StatusOK, tkn)
 			return
 		}
 
@@ -277,17 +798,11 @@ func ServeWith(opts Options) (*gin.Engine, error) {
 		c.String(http.StatusBadRequest, badRequest)
 	})
 
-	var err error
-	if opts.AutoStart {
-		err = r.Run(":" + strconv.Itoa(opts.Port))
-	}
-
-	return r, err
+	return r, nil
 }
 
-func injectGlobalMiddleware(r *gin.Engine, opts Options) {
-	logger, _ := zap.NewProduction()
-	r.Use(middleware.ZapLogger(logger), middleware.ZapRecovery(logger))
+func injectGlobalMiddleware(r *gin.Engine, opts Options, logger *zap.Logger, zapOpts ...middleware.ZapOption) {
+	r.Use(middleware.ZapLogger(logger, zapOpts...), middleware.ZapRecovery(logger))
 
 	if opts.Pretty {
 		r.Use(middleware.PrettyJSON())
@@ -296,52 +811,163 @@ func injectGlobalMiddleware(r *gin.Engine, opts Options) {
 	}
 }
 
-func selectAuthMiddleware(opts Options) gin.HandlerFunc {
+func selectAuthMiddleware(opts Options, verifier token.Verifier, v1Audit *audit.V1, logger *zap.Logger) gin.HandlerFunc {
 	if opts.IMDSv2 {
-		return middleware.StrictV2()
+		return middleware.StrictV2(verifier)
+	}
+
+	if v1Audit != nil {
+		return middleware.V1OptionalV2(verifier, middleware.WithV1Audit(v1Audit, logger))
 	}
 
-	return middleware.V1OptionalV2()
+	return middleware.V1OptionalV2(verifier)
 }
 
-func keys(json []byte, path string) string {
+func keys(json []byte, path string, reserved reservedPaths) string {
 	// Scan the JSON document, retrieving all of the top-level fields as keys
-	query := "@keys"
+	res := gjson.ParseBytes(json)
 	if path != "" {
-		query = path + ".@keys"
+		res = gjson.GetBytes(json, path)
 	}
-	keys := gjson.GetBytes(json, query).Array()
 
-	categories := make([]string, 0, len(keys))
-	for _, key := range keys {
+	var categories []string
+	res.ForEach(func(key, value gjson.Result) bool {
+		// Elements of an array are addressed by their index
 		k := key.String()
-
-		// IMDS service returns a category with a trailing slash, if it is a parent category
-		query = k
-		if path != "" {
-			query = path + "." + k
+		if res.IsArray() {
+			k = strconv.Itoa(len(categories))
 		}
 
-		if gjson.GetBytes(json, query).IsObject() {
+		// IMDS service returns a category with a trailing slash, if it is a parent category.
+		// Reserved paths return JSON and are therefore not a parent category
+		if isParent(value) && !reserved.contains(joinPath(path, gjson.Escape(k))) {
 			k = k + "/"
 		}
 
 		categories = append(categories, k)
-	}
+		return true
+	})
 
 	return strings.Join(categories, "\n")
 }
 
-func isReservedPathChild(path string) bool {
-	for key := range reservedPaths {
-		if strings.HasPrefix(path, key) && path != key {
+func (r reservedPaths) isChild(path string) bool {
+	for key := range r {
+		if strings.HasPrefix(path, key+".") {
 			return true
 		}
 	}
 	return false
 }
 
-func notReservedPath(path string) bool {
-	_, ok := reservedPaths[path]
-	return !ok
+func (r reservedPaths) contains(path string) bool {
+	_, ok := r[path]
+	return ok
+}
+
+func loadMetadata(metadata *patchedJSON, opts Options) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(opts.Metadata, &doc); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := position(opts.Metadata, syntaxErr.Offset)
+			return fmt.Errorf("metadata is not valid JSON: %s (line %d, column %d)", syntaxErr, line, col)
+		}
+
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return errors.New("metadata must be a JSON object")
+		}
+
+		return fmt.Errorf("metadata is not valid JSON: %w", err)
+	}
+
+	if doc == nil {
+		return errors.New("metadata must be a JSON object")
+	}
+
+	if opts.MergeMetadata {
+		return metadata.Patch(patch.Merge{Document: opts.Metadata})
+	}
+
+	metadata.data = opts.Metadata
+	return nil
+}
+
+// Filters out any instance tag already defined within the metadata document
+func undefinedTags(metadata []byte, tags map[string]string) map[string]string {
+	existing := gjson.GetBytes(metadata, "tags.instance")
+	if !existing.IsObject() {
+		return tags
+	}
+
+	filtered := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if !existing.Get(gjson.Escape(k)).Exists() {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
+
+// Converts a byte offset within a document into a line and column number
+func position(data []byte, offset int64) (int, int) {
+	line, col := 1, 1
+	for i := int64(0); i < offset-1 && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+
+	return line, col
+}
+
+func (m *Mock) exposeIAMRole() error {
+	opts := m.opts
+	if opts.ExcludeIAM {
+		// Ensure all IAM categories are removed
+		return m.metadata.Patch(patch.IAM{})
+	}
+
+	if opts.CredentialsTTL < 0 {
+		return errors.New("iam credentials ttl must be greater than zero")
+	}
+
+	instanceProfileArn := opts.InstanceProfileArn
+	if instanceProfileArn == "" {
+		mac := gjson.GetBytes(m.metadata.Bytes(), "mac").String()
+		accountID := gjson.GetBytes(m.metadata.Bytes(), "network.interfaces.macs."+gjson.Escape(mac)+".owner-id").String()
+
+		instanceProfileArn = fmt.Sprintf("arn:aws:iam::%s:instance-profile/%s", accountID, opts.IAMRole)
+	}
+
+	iamPatch := patch.IAM{
+		Role:        opts.IAMRole,
+		Info:        iam.NewInfo(instanceProfileArn),
+		Credentials: iam.NewCredentials(opts.CredentialsTTL),
+	}
+
+	if err := m.metadata.Patch(iamPatch); err != nil {
+		return err
+	}
+
+	var rotate func()
+	rotate = func() {
+		iamPatch.Credentials = iam.NewCredentials(opts.CredentialsTTL)
+		iamPatch.Info.LastUpdated = iamPatch.Credentials.LastUpdated
+
+		if err := m.metadata.Patch(iamPatch); err != nil {
+			return
+		}
+		m.metrics.EventFired("iam-credentials-rotation")
+
+		m.events.Once(iam.RefreshInterval(opts.CredentialsTTL), rotate)
+	}
+	m.events.Once(iam.RefreshInterval(opts.CredentialsTTL), rotate)
+
+	return nil
 }
@@ -23,19 +23,35 @@ SOFTWARE.
 package imds_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/purpleclay/imds-mock/pkg/imds"
+	"github.com/purpleclay/imds-mock/pkg/imds/iam"
+	"github.com/purpleclay/imds-mock/pkg/imds/identity"
+	"github.com/purpleclay/imds-mock/pkg/imds/logging"
+	"github.com/purpleclay/imds-mock/pkg/imds/middleware"
 	"github.com/purpleclay/imds-mock/pkg/imds/patch"
 	"github.com/purpleclay/imds-mock/pkg/imds/token"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/pretty"
+	"go.mozilla.org/pkcs7"
 )
 
 var testOptions = imds.Options{
@@ -117,7 +133,7 @@ tags/`,
 		{
 			name: "ForSubCategory",
 			path: "/latest/meta-data/iam",
-			expected: `info/
+			expected: `info
 security-credentials/`,
 		},
 	}
@@ -147,6 +163,155 @@ func TestCategoryValueIsString(t *testing.T) {
 	assert.Equal(t, "ami-0e34bbddc66def5ac", w.Body.String())
 }
 
+func TestCategoryValueIsList(t *testing.T) {
+	const macPath = "/latest/meta-data/network/interfaces/macs/06:e5:43:29:8f:08"
+
+	opts := testOptions
+	opts.MergeMetadata = true
+	opts.Metadata = []byte(`{
+	"network": {
+		"interfaces": {
+			"macs": {
+				"06:e5:43:29:8f:08": {
+					"local-ipv4s": ["10.0.1.100", "10.0.1.101"],
+					"ipv6s": ["2001:db8::1", "2001:db8::2"],
+					"security-group-ids": ["sg-083739656b4679c06", "sg-0a1b2c3d4e5f67890"]
+				}
+			}
+		}
+	},
+	"security-groups": ["ssm-sg", "web-sg"]
+}`)
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "SecurityGroups",
+			path:     "/latest/meta-data/security-groups",
+			expected: "ssm-sg\nweb-sg",
+		},
+		{
+			name:     "LocalIPv4s",
+			path:     macPath + "/local-ipv4s",
+			expected: "10.0.1.100\n10.0.1.101",
+		},
+		{
+			name:     "IPv6s",
+			path:     macPath + "/ipv6s",
+			expected: "2001:db8::1\n2001:db8::2",
+		},
+		{
+			name:     "SecurityGroupIDs",
+			path:     macPath + "/security-group-ids",
+			expected: "sg-083739656b4679c06\nsg-0a1b2c3d4e5f67890",
+		},
+		{
+			name:     "SingleValue",
+			path:     macPath + "/vpc-ipv4-cidr-blocks",
+			expected: "10.0.0.0/16",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(t, m.Router(), tt.path)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestCategoryListNotIndexed(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/security-groups/0").Code)
+	assert.NotContains(t, get(t, m.Router(), "/latest/meta-data/").Body.String(), "security-groups/")
+}
+
+func TestCategoryIndexAddressing(t *testing.T) {
+	opts := testOptions
+	opts.MergeMetadata = true
+	opts.Metadata = []byte(`{
+	"public-keys": [
+		{"openssh-key": "ssh-rsa AAAA first"},
+		{"openssh-key": "ssh-rsa BBBB second"}
+	]
+}`)
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		code     int
+		expected string
+	}{
+		{
+			name:     "ParentCategory",
+			path:     "/latest/meta-data/",
+			code:     http.StatusOK,
+			expected: "public-keys/",
+		},
+		{
+			name:     "Indices",
+			path:     "/latest/meta-data/public-keys",
+			code:     http.StatusOK,
+			expected: "0/\n1/",
+		},
+		{
+			name:     "Index",
+			path:     "/latest/meta-data/public-keys/1/",
+			code:     http.StatusOK,
+			expected: "openssh-key",
+		},
+		{
+			name:     "IndexedValue",
+			path:     "/latest/meta-data/public-keys/1/openssh-key",
+			code:     http.StatusOK,
+			expected: "ssh-rsa BBBB second",
+		},
+		{
+			name: "OutOfRange",
+			path: "/latest/meta-data/public-keys/2/",
+			code: http.StatusNotFound,
+		},
+		{
+			name: "NotAnIndex",
+			path: "/latest/meta-data/public-keys/01/",
+			code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(t, m.Router(), tt.path)
+
+			require.Equal(t, tt.code, w.Code)
+			if tt.expected != "" {
+				assert.Contains(t, w.Body.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestMaintenanceEventsAreJSON(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/meta-data/events/maintenance/history")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+
+	assert.Equal(t, "history\nscheduled", get(t, m.Router(), "/latest/meta-data/events/maintenance").Body.String())
+}
+
 func TestCategoryValueIsCompactJSON(t *testing.T) {
 	r, _ := imds.ServeWith(testOptions)
 
@@ -157,12 +322,8 @@ func TestCategoryValueIsCompactJSON(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "text/plain", w.Result().Header["Content-Type"][0])
-	assert.Equal(t, string(pretty.Ugly([]byte(`{
-	"Code": "Success",
-	"LastUpdated": "2022-08-08T04:25:36Z",
-	"InstanceProfileArn": "arn:aws:iam::112233445566:instance-profile/ssm-access",
-	"InstanceProfileId": "AIPAYUKXDENX4ZNCZWHF6"
-}`))), w.Body.String())
+	assert.Equal(t, string(pretty.Ugly(w.Body.Bytes())), w.Body.String())
+	assert.Contains(t, w.Body.String(), `"InstanceProfileArn":"arn:aws:iam::112233445566:instance-profile/ssm-access"`)
 }
 
 func TestCategoryValueIsPrettyJSON(t *testing.T) {
@@ -176,15 +337,18 @@ func TestCategoryValueIsPrettyJSON(t *testing.T) {
 
 	r.ServeHTTP(w, req)
 
+	var info iam.Info
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "text/plain", w.Result().Header["Content-Type"][0])
-	assert.Equal(t, `{
+	assert.Equal(t, fmt.Sprintf(`{
   "Code": "Success",
   "InstanceProfileArn": "arn:aws:iam::112233445566:instance-profile/ssm-access",
-  "InstanceProfileId": "AIPAYUKXDENX4ZNCZWHF6",
-  "LastUpdated": "2022-08-08T04:25:36Z"
+  "InstanceProfileId": "%s",
+  "LastUpdated": "%s"
 }
-`, w.Body.String())
+`, info.InstanceProfileID, info.LastUpdated), w.Body.String())
 }
 
 func TestCategoryPathIsChildOfJSON(t *testing.T) {
@@ -277,6 +441,155 @@ func TestInstanceTags(t *testing.T) {
 	}
 }
 
+func TestInstanceTagsLiteralKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "Letters", key: "Name"},
+		{name: "Numbers", key: "2023"},
+		{name: "Space", key: "Cost Centre"},
+		{name: "Plus", key: "a+b"},
+		{name: "Hyphen", key: "cost-centre"},
+		{name: "Equals", key: "a=b"},
+		{name: "Dot", key: "app.version"},
+		{name: "Underscore", key: "cost_centre"},
+		{name: "Colon", key: "aws:cloudformation:stack-name"},
+		{name: "Slash", key: "app.kubernetes.io/name"},
+		{name: "MultipleSlashes", key: "a/b/c"},
+		{name: "At", key: "team@example"},
+		{name: "LeadingAt", key: "@keys"},
+		{name: "Asterisk", key: "a*"},
+		{name: "QuestionMark", key: "a?b"},
+		{name: "Hash", key: "#"},
+		{name: "Pipe", key: "a|b"},
+		{name: "Exclamation", key: "!true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Keys rejected as instance tags can still be exposed through custom
+			// metadata, so the resolver must treat every key literally
+			tags, _ := json.Marshal(map[string]string{
+				tt.key: "value",
+				// Any wildcard or partial match would resolve this tag instead
+				"aaa": "decoy",
+			})
+
+			opts := testOptions
+			opts.MergeMetadata = true
+			opts.Metadata = []byte(`{"tags":{"instance":` + string(tags) + `}}`)
+
+			m, err := imds.New(opts)
+			require.NoError(t, err)
+
+			w := get(t, m.Router(), "/latest/meta-data/tags/instance/"+url.PathEscape(tt.key))
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "value", w.Body.String())
+
+			keys := get(t, m.Router(), "/latest/meta-data/tags/instance").Body.String()
+			assert.Contains(t, strings.Split(keys, "\n"), tt.key)
+		})
+	}
+}
+
+func TestInstanceTagsSlashedKeyPrecedence(t *testing.T) {
+	opts := testOptions
+	opts.MergeMetadata = true
+	opts.Metadata = []byte(`{"tags":{"instance":{"app.kubernetes.io/name":"imds-mock","app.kubernetes.io":"not an object"}}}`)
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/meta-data/tags/instance/app.kubernetes.io/name")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "imds-mock", w.Body.String())
+
+	w = get(t, m.Router(), "/latest/meta-data/tags/instance/app.kubernetes.io")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "not an object", w.Body.String())
+}
+
+func TestInstanceTagsInvalid(t *testing.T) {
+	opts := testOptions
+	opts.InstanceTags = map[string]string{
+		"app.kubernetes.io/name": "imds-mock",
+	}
+
+	_, err := imds.New(opts)
+	require.EqualError(t, err, `invalid instance tag key "app.kubernetes.io/name": character '/' is not supported by IMDS, expecting letters, numbers or + - = . , _ : @`)
+}
+
+func TestInstanceTagsEscapedValues(t *testing.T) {
+	opts := testOptions
+	opts.InstanceTags = map[string]string{
+		"Description": `a "quoted" C:\path`,
+	}
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/meta-data/tags/instance/Description")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `a "quoted" C:\path`, w.Body.String())
+}
+
+func TestCategoryPathWildcardsNotExpanded(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	for _, path := range []string{"/latest/meta-data/instance-*", "/latest/meta-data/instance-i?", "/latest/meta-data/placement/@keys", "/latest/meta-data/tags/instance/" + url.PathEscape("#")} {
+		assert.Equal(t, http.StatusNotFound, get(t, m.Router(), path).Code, path)
+	}
+}
+
+func TestInstanceTagsMergedWithCustomMetadata(t *testing.T) {
+	opts := testOptions
+	opts.MergeMetadata = true
+	opts.Metadata = []byte(`{"tags":{"instance":{"Team":"platform"}}}`)
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/meta-data/tags/instance")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.ElementsMatch(t, []string{"Name", "Team"}, strings.Split(w.Body.String(), "\n"))
+
+	assert.Equal(t, "imds-mock-ec2", get(t, m.Router(), "/latest/meta-data/tags/instance/Name").Body.String())
+	assert.Equal(t, "platform", get(t, m.Router(), "/latest/meta-data/tags/instance/Team").Body.String())
+}
+
+func TestInstanceTagsCustomMetadataPrecedence(t *testing.T) {
+	opts := testOptions
+	opts.Metadata = []byte(`{"instance-id":"i-0123456789abcdef0","tags":{"instance":{"Name":"custom"}}}`)
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, "custom", get(t, m.Router(), "/latest/meta-data/tags/instance/Name").Body.String())
+}
+
+func TestInstanceTagsPatchedAtRuntime(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Patch(patch.InstanceTag{Tags: map[string]string{"Name": "changed"}}))
+
+	assert.Equal(t, "changed", get(t, m.Router(), "/latest/meta-data/tags/instance/Name").Body.String())
+}
+
+func TestExcludeInstanceTagsRemovesCustomMetadata(t *testing.T) {
+	opts := testOptions
+	opts.ExcludeInstanceTags = true
+	opts.MergeMetadata = true
+	opts.Metadata = []byte(`{"tags":{"instance":{"Team":"platform"}}}`)
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/tags").Code)
+	assert.NotContains(t, get(t, m.Router(), "/latest/meta-data").Body.String(), "tags")
+}
+
 func TestExcludeInstanceTags(t *testing.T) {
 	opts := testOptions
 	opts.ExcludeInstanceTags = true
@@ -302,6 +615,44 @@ func TestAPIToken(t *testing.T) {
 	assert.NotEmpty(t, w.Body.String())
 }
 
+func TestJournalRecordsTokenUsage(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/tags/instance", http.NoBody)
+	req.Header.Set(middleware.V2TokenHeader, mustToken(t, m))
+	m.Router().ServeHTTP(w, req)
+
+	get(t, m.Router(), "/latest/meta-data/instance-id")
+
+	entries := m.Journal().Entries()
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, http.MethodPut, entries[0].Method)
+	assert.Equal(t, "/latest/api/token", entries[0].Path)
+
+	assert.Equal(t, "/latest/meta-data/tags/instance", entries[1].Path)
+	assert.True(t, entries[1].TokenProvided)
+	assert.True(t, entries[1].TokenValid)
+
+	// An IMDSv1 request
+	assert.Equal(t, "/latest/meta-data/instance-id", entries[2].Path)
+	assert.False(t, entries[2].TokenProvided)
+}
+
+func mustToken(t *testing.T, m *imds.Mock) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/latest/api/token", http.NoBody)
+	req.Header.Add(imds.V2TokenTTLHeader, "10")
+	m.Router().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	return w.Body.String()
+}
+
 func TestAPITokenIMDSv2(t *testing.T) {
 	opts := testOptions
 	opts.IMDSv2 = true
@@ -376,6 +727,87 @@ func TestAPIToken_BadTTL(t *testing.T) {
 	}
 }
 
+func TestAPIToken_MaxTTL(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	tests := []struct {
+		ttl  int
+		code int
+	}{
+		{ttl: token.MaxTTLInSeconds, code: http.StatusOK},
+		{ttl: token.MaxTTLInSeconds + 1, code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/latest/api/token", http.NoBody)
+		req.Header.Add(imds.V2TokenTTLHeader, strconv.Itoa(tt.ttl))
+		m.Router().ServeHTTP(w, req)
+
+		assert.Equal(t, tt.code, w.Code, tt.ttl)
+	}
+}
+
+func TestAPITokenForgedRejected(t *testing.T) {
+	opts := testOptions
+	opts.IMDSv2 = true
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	tkn := mustToken(t, m)
+	raw, _ := base64.StdEncoding.DecodeString(tkn)
+	raw[len(raw)-1] ^= 1
+
+	forged, _ := json.Marshal(token.NewV2(token.MaxTTLInSeconds))
+
+	for _, tt := range []string{
+		base64.StdEncoding.EncodeToString(forged),
+		base64.StdEncoding.EncodeToString(raw),
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+		req.Header.Set(middleware.V2TokenHeader, tt)
+		m.Router().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestAPITokenSecret(t *testing.T) {
+	opts := testOptions
+	opts.IMDSv2 = true
+	opts.TokenSecret = "pinned"
+
+	issuer, err := imds.New(opts)
+	require.NoError(t, err)
+	tkn := mustToken(t, issuer)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   int
+	}{
+		{name: "SameSecret", secret: "pinned", code: http.StatusOK},
+		{name: "DifferentSecret", secret: "another", code: http.StatusUnauthorized},
+		{name: "ProcessSecret", secret: "", code: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts.TokenSecret = tt.secret
+			m, err := imds.New(opts)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+			req.Header.Set(middleware.V2TokenHeader, tkn)
+			m.Router().ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
 func TestNoSpotCategoriesByDefault(t *testing.T) {
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/spot/instance-action", http.NoBody)
@@ -422,3 +854,807 @@ func TestSpotSimulationWithDelay(t *testing.T) {
 	require.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), `"action":"hibernate"`)
 }
+
+func TestSpotSimulationTimeline(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotAction = imds.SpotActionEvent{
+		Action:   patch.StopSpotInstanceAction,
+		Duration: 300 * time.Millisecond,
+	}
+	opts.SpotRebalanceLead = 250 * time.Millisecond
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, "spot", get(t, m.Router(), "/latest/meta-data/instance-life-cycle").Body.String())
+	assert.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/events/recommendations/rebalance").Code)
+
+	// Crude sleep to ensure the rebalance recommendation is raised ahead of the notice
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, get(t, m.Router(), "/latest/meta-data/events/recommendations/rebalance").Code)
+	assert.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/spot/instance-action").Code)
+	assert.Equal(t, "maintenance/\nrecommendations/", get(t, m.Router(), "/latest/meta-data/events").Body.String())
+
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, get(t, m.Router(), "/latest/meta-data/spot/instance-action").Code)
+}
+
+func TestSpotSimulationNoticeLead(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotNoticeLead = 30 * time.Second
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/meta-data/spot/instance-action")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	actionTime, err := time.Parse(time.RFC3339, gjson.Get(w.Body.String(), "time").String())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), actionTime, 2*time.Second)
+}
+
+func TestSpotTermination(t *testing.T) {
+	tests := []struct {
+		name string
+		mode imds.TerminationMode
+	}{
+		{
+			name: "Refuse",
+			mode: imds.RefuseTermination,
+		},
+		{
+			name: "Hang",
+			mode: imds.HangTermination,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testOptions
+			opts.Spot = true
+			opts.SpotAction = imds.SpotActionEvent{Action: patch.StopSpotInstanceAction}
+			opts.SpotNoticeLead = time.Millisecond
+			opts.SpotTermination = tt.mode
+			opts.HARFile = filepath.Join(t.TempDir(), "imds.har")
+
+			m, err := imds.New(opts)
+			require.NoError(t, err)
+
+			srv := httptest.NewServer(m.Router())
+			defer srv.Close()
+
+			client := http.Client{Timeout: 200 * time.Millisecond}
+			_, err = client.Get(srv.URL + "/latest/meta-data/spot/instance-action")
+			require.Error(t, err)
+
+			// A terminated instance never responds, so nothing is recorded
+			assert.Empty(t, m.Journal().Entries())
+			assert.Empty(t, m.HAR().Entries())
+			assert.Empty(t, m.Usage().Report().Categories)
+
+			// Withdrawing the notice brings the instance back
+			require.NoError(t, m.WithdrawSpotInterruption())
+
+			resp, err := client.Get(srv.URL + "/latest/meta-data/instance-life-cycle")
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestSpotTerminationBeforeActionTime(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotTermination = imds.RefuseTermination
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, get(t, m.Router(), "/latest/meta-data/spot/instance-action").Code)
+}
+
+func TestSpotNoticeLeadZero(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotNoticeLead = 0
+	opts.SpotAction = imds.SpotActionEvent{Action: patch.StopSpotInstanceAction}
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/meta-data/spot/instance-action")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	actionTime, err := time.Parse(time.RFC3339, gjson.Get(w.Body.String(), "time").String())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(patch.DefaultSpotLeadTime), actionTime, 2*time.Second)
+}
+
+func TestSpotNoticeImmediate(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotNoticeImmediate = true
+	opts.SpotAction = imds.SpotActionEvent{Action: patch.StopSpotInstanceAction}
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/meta-data/spot/instance-action")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	actionTime, err := time.Parse(time.RFC3339, gjson.Get(w.Body.String(), "time").String())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), actionTime, 2*time.Second)
+}
+
+func TestSpotNoticeLeadNegative(t *testing.T) {
+	opts := testOptions
+	opts.Spot = true
+	opts.SpotNoticeLead = -time.Second
+
+	_, err := imds.New(opts)
+	require.EqualError(t, err, "spot notice lead cannot be negative")
+}
+
+func TestParseTerminationMode(t *testing.T) {
+	mode, err := imds.ParseTerminationMode("hang")
+	require.NoError(t, err)
+	assert.Equal(t, imds.HangTermination, mode)
+
+	_, err = imds.ParseTerminationMode("explode")
+	require.EqualError(t, err, "explode is not a supported termination mode expecting (refuse, hang or exit)")
+}
+
+func TestNewHandler(t *testing.T) {
+	h, closeHandler, err := imds.NewHandler(testOptions)
+	require.NoError(t, err)
+	defer closeHandler()
+
+	mux := http.NewServeMux()
+	mux.Handle("/latest/", h)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/latest/meta-data/instance-id")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "i-0decb1524582da041", string(body))
+}
+
+func TestNewHandlerBehindPrefix(t *testing.T) {
+	h, closeHandler, err := imds.NewHandler(testOptions)
+	require.NoError(t, err)
+	defer closeHandler()
+
+	w := get(t, http.StripPrefix("/imds", h), "/imds/latest/meta-data/local-ipv4")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "10.0.1.100", w.Body.String())
+}
+
+func TestNewHandlerInvalidOptions(t *testing.T) {
+	opts := testOptions
+	opts.Metadata = []byte(`[]`)
+
+	_, _, err := imds.NewHandler(opts)
+	require.EqualError(t, err, "metadata must be a JSON object")
+}
+
+func TestUserData(t *testing.T) {
+	opts := testOptions
+	opts.UserData = []byte(`#!/bin/bash
+echo "hello, world"`)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/user-data", http.NoBody)
+
+	r, _ := imds.ServeWith(opts)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/octet-stream", w.Result().Header["Content-Type"][0])
+	assert.Equal(t, `#!/bin/bash
+echo "hello, world"`, w.Body.String())
+}
+
+func TestUserData_Binary(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`{"user": "data"}`))
+	gz.Close()
+
+	opts := testOptions
+	opts.UserData = buf.Bytes()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/user-data", http.NoBody)
+
+	r, _ := imds.ServeWith(opts)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, buf.Bytes(), w.Body.Bytes())
+}
+
+func TestUserData_JSONNotFormatted(t *testing.T) {
+	opts := testOptions
+	opts.Pretty = true
+	opts.UserData = []byte(`{"user": "data"}`)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/user-data", http.NoBody)
+
+	r, _ := imds.ServeWith(opts)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"user": "data"}`, w.Body.String())
+}
+
+func TestUserData_NotConfigured(t *testing.T) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/user-data", http.NoBody)
+
+	r, _ := imds.ServeWith(testOptions)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "text/html", w.Result().Header["Content-Type"][0])
+}
+
+func TestUserData_IMDSv2(t *testing.T) {
+	opts := testOptions
+	opts.IMDSv2 = true
+	opts.UserData = []byte("user-data")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/user-data", http.NoBody)
+
+	r, _ := imds.ServeWith(opts)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestDynamicKeys(t *testing.T) {
+	r, _ := imds.ServeWith(testOptions)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "ForRoot",
+			path:     "/latest/dynamic",
+			expected: "instance-identity/",
+		},
+		{
+			name:     "ForRootTrailingSlash",
+			path:     "/latest/dynamic/",
+			expected: "instance-identity/",
+		},
+		{
+			name: "ForInstanceIdentity",
+			path: "/latest/dynamic/instance-identity/",
+			expected: `document
+pkcs7
+rsa2048
+signature`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, http.NoBody)
+
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestInstanceIdentityDocument(t *testing.T) {
+	r, _ := imds.ServeWith(testOptions)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/dynamic/instance-identity/document", http.NoBody)
+
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain", w.Result().Header["Content-Type"][0])
+
+	var doc identity.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+
+	assert.Equal(t, "112233445566", doc.AccountID)
+	assert.Equal(t, "us-east-1a", doc.AvailabilityZone)
+	assert.Equal(t, "ami-0e34bbddc66def5ac", doc.ImageID)
+	assert.Equal(t, "i-0decb1524582da041", doc.InstanceID)
+	assert.Equal(t, "m4.xlarge", doc.InstanceType)
+	assert.Equal(t, "10.0.1.100", doc.PrivateIP)
+	assert.Equal(t, "us-east-1", doc.Region)
+}
+
+func TestInstanceIdentitySignature(t *testing.T) {
+	signer, err := identity.NewSigner()
+	require.NoError(t, err)
+
+	opts := testOptions
+	opts.IdentityKey = signer.KeyPEM()
+	opts.IdentityCertificate = signer.CertificatePEM()
+
+	r, _ := imds.ServeWith(opts)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/dynamic/instance-identity/document", http.NoBody)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	doc := w.Body.Bytes()
+
+	for _, category := range []string{"pkcs7", "rsa2048"} {
+		t.Run(category, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/latest/dynamic/instance-identity/"+category, http.NoBody)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			der, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(w.Body.String(), "\n", ""))
+			require.NoError(t, err)
+
+			p7, err := pkcs7.Parse(der)
+			require.NoError(t, err)
+			p7.Content = doc
+
+			assert.NoError(t, p7.Verify())
+		})
+	}
+}
+
+func TestInstanceIdentity_MismatchedKeyPair(t *testing.T) {
+	first, err := identity.NewSigner()
+	require.NoError(t, err)
+
+	second, err := identity.NewSigner()
+	require.NoError(t, err)
+
+	opts := testOptions
+	opts.IdentityKey = first.KeyPEM()
+	opts.IdentityCertificate = second.CertificatePEM()
+
+	_, err = imds.ServeWith(opts)
+	require.Error(t, err)
+}
+
+func TestDynamicCategoryDoesNotExist(t *testing.T) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/dynamic/unknown", http.NoBody)
+
+	r, _ := imds.ServeWith(testOptions)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestIAMSecurityCredentials(t *testing.T) {
+	opts := testOptions
+	opts.IAMRole = "testing"
+	opts.InstanceProfileArn = "arn:aws:iam::123456789012:instance-profile/testing-profile"
+	opts.CredentialsTTL = 1 * time.Hour
+
+	r, _ := imds.ServeWith(opts)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/iam/security-credentials", http.NoBody)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "testing", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/latest/meta-data/iam/security-credentials/testing", http.NoBody)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var creds iam.Credentials
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &creds))
+	expiration, err := time.Parse(time.RFC3339, creds.Expiration)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(1*time.Hour), expiration, 1*time.Second)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/latest/meta-data/iam/info", http.NoBody)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"InstanceProfileArn":"arn:aws:iam::123456789012:instance-profile/testing-profile"`)
+}
+
+func TestIAMSecurityCredentials_Rotated(t *testing.T) {
+	opts := testOptions
+	opts.CredentialsTTL = 200 * time.Millisecond
+
+	r, _ := imds.ServeWith(opts)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/iam/security-credentials/ssm-access", http.NoBody)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var before iam.Credentials
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &before))
+
+	// Crude sleep to ensure credentials are rotated
+	time.Sleep(150 * time.Millisecond)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var after iam.Credentials
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &after))
+	assert.NotEqual(t, before.AccessKeyID, after.AccessKeyID)
+}
+
+func TestIAMSecurityCredentials_RoleWithDot(t *testing.T) {
+	opts := testOptions
+	opts.IAMRole = "app.role@prod"
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/meta-data/iam/security-credentials/app.role@prod")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, gjson.Get(w.Body.String(), "AccessKeyId").Exists())
+
+	assert.Equal(t, http.StatusNotFound, get(t, m.Router(), "/latest/meta-data/iam/security-credentials/app.role@prod/AccessKeyId").Code)
+}
+
+func TestIAMDefaultRole(t *testing.T) {
+	m, err := imds.New(imds.Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, iam.DefaultRole, get(t, m.Router(), "/latest/meta-data/iam/security-credentials").Body.String())
+
+	w := get(t, m.Router(), "/latest/meta-data/iam/security-credentials/"+iam.DefaultRole)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var creds iam.Credentials
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &creds))
+	expiration, err := time.Parse(time.RFC3339, creds.Expiration)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(iam.DefaultCredentialsTTL), expiration, 1*time.Second)
+}
+
+func TestExcludeIAM(t *testing.T) {
+	opts := testOptions
+	opts.ExcludeIAM = true
+
+	r, _ := imds.ServeWith(opts)
+
+	for _, path := range []string{"/latest/meta-data/iam", "/latest/meta-data/iam/info", "/latest/meta-data/iam/security-credentials/ssm-access"} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, path, http.NoBody)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+	}
+}
+
+func TestIAMInvalidCredentialsTTL(t *testing.T) {
+	opts := testOptions
+	opts.CredentialsTTL = -time.Second
+
+	_, err := imds.ServeWith(opts)
+	require.EqualError(t, err, "iam credentials ttl must be greater than zero")
+}
+
+func TestMetadataReplaced(t *testing.T) {
+	opts := testOptions
+	opts.ExcludeInstanceTags = true
+	opts.ExcludeIAM = true
+	opts.Metadata = []byte(`{
+	"ami-id": "ami-12345678",
+	"instance-type": "t3.micro"
+}`)
+
+	r, _ := imds.ServeWith(opts)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data", http.NoBody)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `ami-id
+instance-type`, w.Body.String())
+}
+
+func TestMetadataMerged(t *testing.T) {
+	opts := testOptions
+	opts.MergeMetadata = true
+	opts.Metadata = []byte(`{
+	"ami-id": "ami-12345678",
+	"placement": {
+		"region": "eu-west-2"
+	},
+	"profile": null
+}`)
+
+	r, _ := imds.ServeWith(opts)
+
+	tests := []struct {
+		name     string
+		path     string
+		code     int
+		expected string
+	}{
+		{
+			name:     "Overridden",
+			path:     "/latest/meta-data/ami-id",
+			code:     http.StatusOK,
+			expected: "ami-12345678",
+		},
+		{
+			name:     "NestedOverride",
+			path:     "/latest/meta-data/placement/region",
+			code:     http.StatusOK,
+			expected: "eu-west-2",
+		},
+		{
+			name:     "NestedUnchanged",
+			path:     "/latest/meta-data/placement/availability-zone",
+			code:     http.StatusOK,
+			expected: "us-east-1a",
+		},
+		{
+			name:     "Unchanged",
+			path:     "/latest/meta-data/instance-type",
+			code:     http.StatusOK,
+			expected: "m4.xlarge",
+		},
+		{
+			name: "Removed",
+			path: "/latest/meta-data/profile",
+			code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.code, w.Code)
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMetadataInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		errMsg   string
+	}{
+		{
+			name: "MalformedJSON",
+			metadata: `{
+	"ami-id": "ami-12345678",
+}`,
+			errMsg: "metadata is not valid JSON: invalid character '}' looking for beginning of object key string (line 3, column 1)",
+		},
+		{
+			name:     "Truncated",
+			metadata: `{"ami-id": `,
+			errMsg:   "metadata is not valid JSON: unexpected end of JSON input",
+		},
+		{
+			name:     "NotAnObject",
+			metadata: `["ami-12345678"]`,
+			errMsg:   "metadata must be a JSON object",
+		},
+		{
+			name:     "Null",
+			metadata: `null`,
+			errMsg:   "metadata must be a JSON object",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testOptions
+			opts.Metadata = []byte(tt.metadata)
+
+			_, err := imds.ServeWith(opts)
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	opts := testOptions
+	opts.LogFormat = logging.ConsoleFormat
+	opts.LogFile = filepath.Join(t.TempDir(), "imds-mock.log")
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	m.Router().ServeHTTP(w, req)
+	m.Close()
+
+	out, err := os.ReadFile(opts.LogFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "GET /latest/meta-data/instance-id")
+}
+
+func TestLogLevelOff(t *testing.T) {
+	opts := testOptions
+	opts.LogLevel = logging.OffLevel
+	opts.LogFile = filepath.Join(t.TempDir(), "imds-mock.log")
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	m.Router().ServeHTTP(w, req)
+	m.Close()
+
+	assert.NoFileExists(t, opts.LogFile)
+}
+
+func TestLogLevelUnsupported(t *testing.T) {
+	opts := testOptions
+	opts.LogLevel = "trace"
+
+	_, err := imds.New(opts)
+	require.EqualError(t, err, "trace is not a supported log level expecting (debug, info, warn, error or off)")
+}
+
+func TestHARFile(t *testing.T) {
+	opts := testOptions
+	opts.HARFile = filepath.Join(t.TempDir(), "imds-mock.har")
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	tkn := mustToken(t, m)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	req.Header.Set(middleware.V2TokenHeader, tkn)
+	m.Router().ServeHTTP(w, req)
+	m.Close()
+
+	data, err := os.ReadFile(opts.HARFile)
+	require.NoError(t, err)
+
+	entries := gjson.GetBytes(data, "log.entries")
+	require.Len(t, entries.Array(), 2)
+
+	assert.Equal(t, http.MethodPut, entries.Get("0.request.method").String())
+	assert.Equal(t, tkn, entries.Get("0.response.content.text").String())
+	assert.True(t, strings.HasSuffix(entries.Get("1.request.url").String(), "/latest/meta-data/instance-id"))
+	assert.Equal(t, tkn, entries.Get(`1.request.headers.#(name=="X-Aws-Ec2-Metadata-Token").value`).String())
+	assert.Equal(t, w.Body.String(), entries.Get("1.response.content.text").String())
+}
+
+func TestHARFileBinaryUserData(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("#!/bin/bash\necho hello"))
+	require.NoError(t, zw.Close())
+
+	opts := testOptions
+	opts.UserData = gz.Bytes()
+	opts.HARFile = filepath.Join(t.TempDir(), "imds-mock.har")
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := get(t, m.Router(), "/latest/user-data")
+	require.Equal(t, gz.Bytes(), w.Body.Bytes())
+	m.Close()
+
+	data, err := os.ReadFile(opts.HARFile)
+	require.NoError(t, err)
+
+	content := gjson.GetBytes(data, "log.entries.0.response.content")
+	assert.Equal(t, "base64", content.Get("encoding").String())
+
+	decoded, err := base64.StdEncoding.DecodeString(content.Get("text").String())
+	require.NoError(t, err)
+	assert.Equal(t, gz.Bytes(), decoded)
+}
+
+func TestHARNotConfigured(t *testing.T) {
+	m, err := imds.New(testOptions)
+	require.NoError(t, err)
+
+	assert.Nil(t, m.HAR())
+}
+
+func TestUsageReportLoggedOnClose(t *testing.T) {
+	opts := testOptions
+	opts.UsageReport = true
+	opts.LogFile = filepath.Join(t.TempDir(), "imds-mock.log")
+
+	m, err := imds.New(opts)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/latest/meta-data/instance-id", http.NoBody)
+	m.Router().ServeHTTP(w, req)
+	m.Close()
+
+	out, err := os.ReadFile(opts.LogFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"msg":"category usage report","categories":[{"category":"meta-data/instance-id","count":1,"v1":1,"v2":0}]`)
+}
+
+func TestPatchInvalidatesCache(t *testing.T) {
+	const macPath = "/latest/meta-data/network/interfaces/macs/06:e5:43:29:8f:08"
+
+	tests := []struct {
+		name     string
+		path     string
+		patch    patch.JSONPatcher
+		expected string
+	}{
+		{
+			name:     "SpotLifeCycle",
+			path:     "/latest/meta-data/instance-life-cycle",
+			patch:    patch.SpotLifeCycle{},
+			expected: "spot",
+		},
+		{
+			name:     "NestedValue",
+			path:     macPath + "/local-ipv4s",
+			patch:    patch.JSON{Document: []byte(`[{"op": "replace", "path": "/network/interfaces/macs/06:e5:43:29:8f:08/local-ipv4s", "value": "10.0.1.200"}]`)},
+			expected: "10.0.1.200",
+		},
+		{
+			name:     "NestedKeys",
+			path:     macPath + "/",
+			patch:    patch.Merge{Document: []byte(`{"network": {"interfaces": {"macs": {"06:e5:43:29:8f:08": {"ipv6s": "2001:db8::1"}}}}}`)},
+			expected: "ipv6s",
+		},
+		{
+			name:     "ParentKeys",
+			path:     "/latest/meta-data/",
+			patch:    patch.Spot{InstanceAction: patch.TerminateSpotInstanceAction},
+			expected: "spot/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := imds.New(testOptions)
+			require.NoError(t, err)
+
+			// Ensure the response is cached before patching
+			before := get(t, m.Router(), tt.path).Body.String()
+			require.Equal(t, before, get(t, m.Router(), tt.path).Body.String())
+
+			require.NoError(t, m.Patch(tt.patch))
+
+			after := get(t, m.Router(), tt.path).Body.String()
+			assert.NotEqual(t, before, after)
+			assert.Contains(t, after, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpleclay/imds-mock/pkg/imds/patch"
+	"github.com/tidwall/gjson"
+)
+
+// TerminationMode defines how the IMDS mock behaves once a spot instance
+// has been interrupted
+type TerminationMode string
+
+const (
+	// NoTermination keeps the IMDS mock serving requests after a spot
+	// instance has been interrupted
+	NoTermination TerminationMode = ""
+
+	// RefuseTermination closes any connection to the IMDS mock without
+	// a response after a spot instance has been interrupted
+	RefuseTermination TerminationMode = "refuse"
+
+	// HangTermination holds any connection to the IMDS mock open without
+	// a response after a spot instance has been interrupted
+	HangTermination TerminationMode = "hang"
+
+	// ExitTermination exits the IMDS mock process with TerminatedExitCode
+	// once a spot instance has been interrupted
+	ExitTermination TerminationMode = "exit"
+
+	// TerminatedExitCode is the exit code of the IMDS mock process when
+	// a spot instance is interrupted using ExitTermination
+	TerminatedExitCode = 143
+)
+
+// ParseTerminationMode converts a string into a supported termination mode
+func ParseTerminationMode(mode string) (TerminationMode, error) {
+	switch terminationMode := TerminationMode(mode); terminationMode {
+	case NoTermination, RefuseTermination, HangTermination, ExitTermination:
+		return terminationMode, nil
+	}
+
+	return "", fmt.Errorf("%s is not a supported termination mode expecting (%s, %s or %s)",
+		mode, RefuseTermination, HangTermination, ExitTermination)
+}
+
+// Swapped out during testing
+var exit = os.Exit
+
+// Tracks when a spot instance will be interrupted by a raised notice
+type termination struct {
+	mode     TerminationMode
+	at       time.Time
+	timer    *time.Timer
+	done     chan struct{}
+	stopOnce sync.Once
+	mu       sync.RWMutex
+
+	// Invoked before the IMDS mock process exits
+	onExit func()
+}
+
+func newTermination(mode TerminationMode) *termination {
+	return &termination{
+		mode: mode,
+		done: make(chan struct{}),
+	}
+}
+
+// Schedule the termination of the instance based on the current interruption notice
+// within the metadata. Only a terminate or stop action will result in termination
+func (t *termination) schedule(metadata []byte) {
+	if t.mode == NoTermination {
+		return
+	}
+
+	notice := gjson.GetBytes(metadata, "spot.instance-action")
+	action := patch.SpotInstanceAction(notice.Get("action").String())
+	at, err := time.Parse(time.RFC3339, notice.Get("time").String())
+
+	if action == patch.HibernateSpotInstanceAction || err != nil {
+		t.cancel()
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.at = at
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+
+	if t.mode == ExitTermination {
+		t.timer = time.AfterFunc(time.Until(at), func() {
+			if t.onExit != nil {
+				t.onExit()
+			}
+			exit(TerminatedExitCode)
+		})
+	}
+}
+
+func (t *termination) cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.at = time.Time{}
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// Cancel any pending termination and release any request held open
+func (t *termination) stop() {
+	t.cancel()
+	t.stopOnce.Do(func() { close(t.done) })
+}
+
+func (t *termination) terminated() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return !t.at.IsZero() && !time.Now().Before(t.at)
+}
+
+// Middleware that prevents the IMDS mock from responding to requests once
+// the instance has been terminated
+func (t *termination) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !t.terminated() {
+			c.Next()
+			return
+		}
+
+		c.Abort()
+
+		switch t.mode {
+		case RefuseTermination:
+			conn, _, err := c.Writer.Hijack()
+			if err != nil {
+				c.Status(http.StatusServiceUnavailable)
+				return
+			}
+			conn.Close()
+		case HangTermination:
+			select {
+			case <-c.Request.Context().Done():
+			case <-t.done:
+			}
+		}
+	}
+}
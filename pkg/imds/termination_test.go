@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package imds
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func spotNotice(action string, at time.Time) []byte {
+	return []byte(fmt.Sprintf(`{"spot":{"instance-action":{"action":"%s","time":"%s"}}}`,
+		action, at.UTC().Format(time.RFC3339)))
+}
+
+func captureExit(t *testing.T) <-chan int {
+	t.Helper()
+
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	t.Cleanup(func() { exit = os.Exit })
+
+	return codes
+}
+
+func TestTerminationExit(t *testing.T) {
+	codes := captureExit(t)
+
+	term := newTermination(ExitTermination)
+	term.schedule(spotNotice("terminate", time.Now()))
+
+	select {
+	case code := <-codes:
+		assert.Equal(t, TerminatedExitCode, code)
+	case <-time.After(time.Second):
+		require.Fail(t, "mock did not exit after instance was terminated")
+	}
+}
+
+func TestTerminationExitClosesMock(t *testing.T) {
+	codes := captureExit(t)
+
+	opts := DefaultOptions
+	opts.AutoStart = false
+	opts.Spot = true
+	opts.SpotTermination = ExitTermination
+	opts.SpotNoticeLead = time.Millisecond
+	opts.HARFile = filepath.Join(t.TempDir(), "imds-mock.har")
+
+	_, err := New(opts)
+	require.NoError(t, err)
+
+	select {
+	case <-codes:
+		assert.FileExists(t, opts.HARFile)
+	case <-time.After(time.Second):
+		require.Fail(t, "mock did not exit after instance was terminated")
+	}
+}
+
+func TestTerminationCancel(t *testing.T) {
+	codes := captureExit(t)
+
+	term := newTermination(ExitTermination)
+	term.schedule(spotNotice("stop", time.Now().Add(time.Second)))
+	term.cancel()
+
+	select {
+	case <-codes:
+		require.Fail(t, "mock exited after termination was cancelled")
+	case <-time.After(1500 * time.Millisecond):
+	}
+	assert.False(t, term.terminated())
+}
+
+func TestTerminationIgnoresHibernate(t *testing.T) {
+	term := newTermination(RefuseTermination)
+	term.schedule(spotNotice("hibernate", time.Now().Add(-time.Minute)))
+
+	assert.False(t, term.terminated())
+}
+
+func TestTerminationDisabled(t *testing.T) {
+	term := newTermination(NoTermination)
+	term.schedule(spotNotice("terminate", time.Now().Add(-time.Minute)))
+
+	assert.False(t, term.terminated())
+}
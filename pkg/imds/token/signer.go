@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const (
+	version   byte = 1
+	nonceSize      = 16
+
+	payloadSize = 1 + 8 + 8 + nonceSize
+	tokenSize   = payloadSize + sha256.Size
+)
+
+var (
+	// ErrInvalid is returned when a session token cannot be decoded or
+	// its signature does not match, indicating it has been tampered with
+	ErrInvalid = errors.New("session token is invalid")
+
+	// ErrExpired is returned when a session token is correctly signed
+	// but its TTL has elapsed
+	ErrExpired = errors.New("session token has expired")
+)
+
+// Verifier verifies an opaque session token, returning the V2 session token
+// it was issued from
+type Verifier interface {
+	Verify(tkn string) (V2, error)
+}
+
+// Generated once, ensuring all tokens issued by this process share the same
+// secret, unless one is explicitly provided
+var processSecret = func() []byte {
+	secret := make([]byte, sha256.Size)
+	if _, err := rand.Read(secret); err != nil {
+		panic("failed to generate session token secret: " + err.Error())
+	}
+	return secret
+}()
+
+// Signer issues and verifies opaque V2 session tokens. Each token is signed
+// using HMAC-SHA256, preventing a client from forging or extending a token
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer using the provided secret. If no secret is
+// provided, a randomly generated per-process secret is used instead
+func NewSigner(secret []byte) *Signer {
+	if len(secret) == 0 {
+		secret = processSecret
+	}
+
+	return &Signer{secret: secret}
+}
+
+// Sign the V2 session token, returning its opaque base64 encoded form
+func (s *Signer) Sign(t V2) string {
+	buf := make([]byte, payloadSize, tokenSize)
+	buf[0] = version
+	binary.BigEndian.PutUint64(buf[1:9], uint64(t.Issued.UnixNano()))
+	binary.BigEndian.PutUint64(buf[9:17], uint64(t.Expire.UnixNano()))
+
+	// Guarantee uniqueness of tokens issued at the same time
+	rand.Read(buf[17:payloadSize])
+
+	return base64.StdEncoding.EncodeToString(append(buf, s.mac(buf)...))
+}
+
+// Verify the opaque session token, returning the V2 session token it was
+// issued from. ErrInvalid is returned if the token has been tampered with
+// and ErrExpired once its TTL has elapsed
+func (s *Signer) Verify(tkn string) (V2, error) {
+	raw, err := base64.StdEncoding.DecodeString(tkn)
+	if err != nil || len(raw) != tokenSize || raw[0] != version {
+		return V2{}, ErrInvalid
+	}
+
+	payload := raw[:payloadSize]
+	if !hmac.Equal(raw[payloadSize:], s.mac(payload)) {
+		return V2{}, ErrInvalid
+	}
+
+	st := V2{
+		Issued: time.Unix(0, int64(binary.BigEndian.Uint64(payload[1:9]))),
+		Expire: time.Unix(0, int64(binary.BigEndian.Uint64(payload[9:17]))),
+	}
+	if st.Expired() {
+		return st, ErrExpired
+	}
+
+	return st, nil
+}
+
+func (s *Signer) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package token_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerVerify(t *testing.T) {
+	signer := token.NewSigner([]byte("testing"))
+	issued := token.NewV2(10)
+
+	st, err := signer.Verify(signer.Sign(issued))
+	require.NoError(t, err)
+
+	assert.True(t, issued.Issued.Equal(st.Issued))
+	assert.True(t, issued.Expire.Equal(st.Expire))
+}
+
+func TestSignerSignUnique(t *testing.T) {
+	signer := token.NewSigner([]byte("testing"))
+	issued := token.NewV2(10)
+
+	assert.NotEqual(t, signer.Sign(issued), signer.Sign(issued))
+}
+
+func TestSignerPinnedSecret(t *testing.T) {
+	tkn := token.NewSigner([]byte("testing")).Sign(token.NewV2(10))
+
+	_, err := token.NewSigner([]byte("testing")).Verify(tkn)
+	assert.NoError(t, err)
+}
+
+func TestSignerProcessSecret(t *testing.T) {
+	tkn := token.NewSigner(nil).Sign(token.NewV2(10))
+
+	_, err := token.NewSigner(nil).Verify(tkn)
+	assert.NoError(t, err)
+}
+
+func TestSignerVerifyErrors(t *testing.T) {
+	signer := token.NewSigner([]byte("testing"))
+	legacy, _ := json.Marshal(token.NewV2(10))
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{
+			name:  "Expired",
+			token: signer.Sign(token.V2{Expire: time.Now().Add(-time.Second)}),
+			err:   token.ErrExpired,
+		},
+		{
+			name:  "Tampered",
+			token: tamper(signer.Sign(token.NewV2(10)), 5),
+			err:   token.ErrInvalid,
+		},
+		{
+			name:  "TamperedSignature",
+			token: tamper(signer.Sign(token.NewV2(10)), 40),
+			err:   token.ErrInvalid,
+		},
+		{
+			name:  "DifferentSecret",
+			token: token.NewSigner([]byte("another")).Sign(token.NewV2(10)),
+			err:   token.ErrInvalid,
+		},
+		{
+			name:  "UnsignedJSON",
+			token: base64.StdEncoding.EncodeToString(legacy),
+			err:   token.ErrInvalid,
+		},
+		{
+			name:  "NotBase64",
+			token: "hello, world",
+			err:   token.ErrInvalid,
+		},
+		{
+			name:  "Empty",
+			token: "",
+			err:   token.ErrInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := signer.Verify(tt.token)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func tamper(tkn string, i int) string {
+	raw, _ := base64.StdEncoding.DecodeString(tkn)
+	raw[i] ^= 1
+	return base64.StdEncoding.EncodeToString(raw)
+}
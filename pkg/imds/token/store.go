@@ -0,0 +1,184 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrRevoked is returned when a session token is correctly signed but has
+// been revoked before its TTL elapsed
+var ErrRevoked = errors.New("session token has been revoked")
+
+// Issued provides a summary of an active session token, without exposing the
+// token itself
+type Issued struct {
+	ID     string    `json:"id"`
+	Issued time.Time `json:"issued"`
+	Expire time.Time `json:"expire"`
+	TTL    int       `json:"ttl"`
+}
+
+// Store tracks every session token issued by a signer, supporting their
+// revocation before their TTL elapses. Tokens issued elsewhere with the same
+// secret are accepted, but can only be revoked in bulk
+type Store struct {
+	signer        *Signer
+	mu            sync.Mutex
+	tokens        map[string]V2
+	revoked       map[string]time.Time
+	revokedBefore time.Time
+}
+
+// NewStore creates an empty store that issues and verifies session tokens
+// using the provided signer
+func NewStore(signer *Signer) *Store {
+	return &Store{
+		signer:  signer,
+		tokens:  map[string]V2{},
+		revoked: map[string]time.Time{},
+	}
+}
+
+// ID returns a stable identifier for a session token, safe to share without
+// exposing the token itself
+func ID(tkn string) string {
+	sum := sha256.Sum256([]byte(tkn))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Issue a new signed session token with a TTL in seconds
+func (s *Store) Issue(seconds int) string {
+	st := NewV2(seconds)
+	tkn := s.signer.Sign(st)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	s.tokens[ID(tkn)] = st
+	return tkn
+}
+
+// Verify the opaque session token, returning the V2 session token it was
+// issued from. Along with any signer errors, ErrRevoked is returned if the
+// token has been revoked
+func (s *Store) Verify(tkn string) (V2, error) {
+	st, err := s.signer.Verify(tkn)
+	if err != nil {
+		return st, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, revoked := s.revoked[ID(tkn)]; revoked || st.Issued.Before(s.revokedBefore) {
+		return st, ErrRevoked
+	}
+
+	return st, nil
+}
+
+// Active returns a summary of all session tokens issued by this store that
+// have neither expired nor been revoked, ordered by when they were issued
+func (s *Store) Active() []Issued {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+
+	now := time.Now()
+	active := make([]Issued, 0, len(s.tokens))
+	for id, st := range s.tokens {
+		active = append(active, Issued{
+			ID:     id,
+			Issued: st.Issued,
+			Expire: st.Expire,
+			TTL:    int(st.Expire.Sub(now).Seconds()),
+		})
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Issued.Equal(active[j].Issued) {
+			return active[i].ID < active[j].ID
+		}
+		return active[i].Issued.Before(active[j].Issued)
+	})
+
+	return active
+}
+
+// Revoke an active session token by its ID. Returns false if no active
+// token exists with that ID
+func (s *Store) Revoke(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+
+	st, ok := s.tokens[id]
+	if !ok {
+		return false
+	}
+
+	delete(s.tokens, id)
+	s.revoked[id] = st.Expire
+	return true
+}
+
+// RevokeAll revokes every session token issued before now, including any
+// issued elsewhere with the same secret. Returns the number of active tokens
+// issued by this store that were revoked
+func (s *Store) RevokeAll() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+
+	n := len(s.tokens)
+	s.tokens = map[string]V2{}
+	s.revoked = map[string]time.Time{}
+	s.revokedBefore = time.Now()
+	return n
+}
+
+// Discard any expired tokens, as they can no longer be verified
+func (s *Store) prune() {
+	now := time.Now()
+	for id, st := range s.tokens {
+		if now.After(st.Expire) {
+			delete(s.tokens, id)
+		}
+	}
+
+	for id, expire := range s.revoked {
+		if now.After(expire) {
+			delete(s.revoked, id)
+		}
+	}
+}
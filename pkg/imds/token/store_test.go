@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2022 Purple Clay
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/purpleclay/imds-mock/pkg/imds/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreIssue(t *testing.T) {
+	store := token.NewStore(token.NewSigner([]byte("testing")))
+
+	tkn := store.Issue(10)
+
+	st, err := store.Verify(tkn)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), st.Expire, time.Second)
+
+	active := store.Active()
+	require.Len(t, active, 1)
+	assert.Equal(t, token.ID(tkn), active[0].ID)
+	assert.True(t, st.Expire.Equal(active[0].Expire))
+	assert.InDelta(t, 10, active[0].TTL, 1)
+}
+
+func TestStoreActiveExcludesExpired(t *testing.T) {
+	store := token.NewStore(token.NewSigner([]byte("testing")))
+	store.Issue(-1)
+	tkn := store.Issue(10)
+
+	active := store.Active()
+	require.Len(t, active, 1)
+	assert.Equal(t, token.ID(tkn), active[0].ID)
+}
+
+func TestStoreRevoke(t *testing.T) {
+	store := token.NewStore(token.NewSigner([]byte("testing")))
+	revoked := store.Issue(10)
+	kept := store.Issue(10)
+
+	require.True(t, store.Revoke(token.ID(revoked)))
+
+	_, err := store.Verify(revoked)
+	assert.ErrorIs(t, err, token.ErrRevoked)
+
+	_, err = store.Verify(kept)
+	assert.NoError(t, err)
+
+	require.Len(t, store.Active(), 1)
+	assert.False(t, store.Revoke(token.ID(revoked)))
+}
+
+func TestStoreRevokeUnknown(t *testing.T) {
+	store := token.NewStore(token.NewSigner([]byte("testing")))
+
+	assert.False(t, store.Revoke("unknown"))
+}
+
+func TestStoreRevokeAll(t *testing.T) {
+	signer := token.NewSigner([]byte("testing"))
+	store := token.NewStore(signer)
+
+	issued := store.Issue(10)
+	store.Issue(10)
+	// Issued elsewhere using the same secret
+	foreign := token.NewStore(signer).Issue(10)
+
+	assert.Equal(t, 2, store.RevokeAll())
+	assert.Empty(t, store.Active())
+
+	for _, tkn := range []string{issued, foreign} {
+		_, err := store.Verify(tkn)
+		assert.ErrorIs(t, err, token.ErrRevoked)
+	}
+
+	_, err := store.Verify(store.Issue(10))
+	assert.NoError(t, err)
+}
+
+func TestStoreVerifySignerErrors(t *testing.T) {
+	store := token.NewStore(token.NewSigner([]byte("testing")))
+
+	_, err := store.Verify(token.NewSigner([]byte("another")).Sign(token.NewV2(10)))
+	assert.ErrorIs(t, err, token.ErrInvalid)
+
+	_, err = store.Verify(store.Issue(-1))
+	assert.ErrorIs(t, err, token.ErrExpired)
+}
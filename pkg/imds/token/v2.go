@@ -29,6 +29,9 @@ const MaxTTLInSeconds = 21600
 
 // V2 defines a session orientated token that embeds a TTL for expiration checks
 type V2 struct {
+	// Issued contains a time and date of when this token was issued
+	Issued time.Time `json:"issued"`
+
 	// Expire contains a time and date of when this token will expire,
 	// resulting in all IMDSv2 bases requests to be rejected
 	Expire time.Time `json:"expire"`
@@ -36,8 +39,10 @@ type V2 struct {
 
 // NewV2 generates a new V2 session token from the provided TTL in seconds
 func NewV2(seconds int) V2 {
+	now := time.Now()
 	return V2{
-		Expire: time.Now().Add(time.Duration(seconds) * time.Second),
+		Issued: now,
+		Expire: now.Add(time.Duration(seconds) * time.Second),
 	}
 }
 